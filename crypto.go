@@ -2,6 +2,7 @@ package nits
 
 import (
 	"crypto"
+	"crypto/ecdh"
 	"crypto/ecdsa"
 	"crypto/ed25519"
 	"crypto/elliptic"
@@ -33,8 +34,9 @@ func (cryptoUtility) MustGenerateKey(privateKey crypto.PrivateKey, err error) cr
 	return privateKey
 }
 
-// CryptographicAlgorithm is an alias of string.
-type CryptographicAlgorithm = string
+// CryptographicAlgorithm is the algorithm of the keys generated by Crypto.GenerateKey.
+// RSA keys of any size can be expressed as "rsa" followed by the number of bits, such as "rsa3072".
+type CryptographicAlgorithm string
 
 const (
 	// CryptoRSA2048 RSA 2048 bits.
@@ -49,34 +51,175 @@ const (
 	CryptoECDSA384 CryptographicAlgorithm = "ecdsa384"
 	// CryptoEd25519 Ed25519.
 	CryptoEd25519 CryptographicAlgorithm = "ed25519"
+	// CryptoX25519 X25519. It is only for key agreement and encryption, not for signing.
+	CryptoX25519 CryptographicAlgorithm = "x25519"
+
+	cryptoRSAPrefix = "rsa"
+)
+
+var (
+	// ErrCryptoWeakCryptographicAlgorithm cryptographic algorithm is too weak.
+	ErrCryptoWeakCryptographicAlgorithm = errors.New("cryptographic algorithm is too weak")
+
+	// ErrCryptoCryptographicAlgorithmExceedsPolicy cryptographic algorithm exceeds policy.
+	ErrCryptoCryptographicAlgorithmExceedsPolicy = errors.New("cryptographic algorithm exceeds policy")
 )
 
+// cryptoAlgorithmAliases maps the names normalized by ParseCryptographicAlgorithm to CryptographicAlgorithm.
+// nolint: gochecknoglobals
+var cryptoAlgorithmAliases = map[string]CryptographicAlgorithm{
+	"rs256":      CryptoRSA2048,
+	"ps256":      CryptoRSA2048,
+	"ecdsa256":   CryptoECDSA256,
+	"ecdsap256":  CryptoECDSA256,
+	"p256":       CryptoECDSA256,
+	"secp256r1":  CryptoECDSA256,
+	"prime256v1": CryptoECDSA256,
+	"es256":      CryptoECDSA256,
+	"ecdsa384":   CryptoECDSA384,
+	"ecdsap384":  CryptoECDSA384,
+	"p384":       CryptoECDSA384,
+	"secp384r1":  CryptoECDSA384,
+	"es384":      CryptoECDSA384,
+	"ed25519":    CryptoEd25519,
+	"eddsa":      CryptoEd25519,
+	"x25519":     CryptoX25519,
+}
+
+// String implements fmt.Stringer.
+func (algorithm CryptographicAlgorithm) String() string {
+	return string(algorithm)
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (algorithm CryptographicAlgorithm) MarshalText() ([]byte, error) {
+	return []byte(algorithm), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. The text is parsed by Crypto.ParseCryptographicAlgorithm.
+func (algorithm *CryptographicAlgorithm) UnmarshalText(text []byte) error {
+	parsed, err := Crypto.ParseCryptographicAlgorithm(string(text))
+	if err != nil {
+		return err
+	}
+
+	*algorithm = parsed
+
+	return nil
+}
+
+// rsaBits returns the number of bits of the RSA algorithm.
+func (algorithm CryptographicAlgorithm) rsaBits() (bits int, ok bool) {
+	if !strings.HasPrefix(string(algorithm), cryptoRSAPrefix) {
+		return 0, false
+	}
+
+	bits, err := strconv.Atoi(strings.TrimPrefix(string(algorithm), cryptoRSAPrefix))
+	if err != nil || bits <= 0 || strconv.Itoa(bits) != strings.TrimPrefix(string(algorithm), cryptoRSAPrefix) {
+		return 0, false
+	}
+
+	return bits, true
+}
+
+// Validate checks the algorithm against Crypto.DefaultCryptographicAlgorithmPolicy.
+func (algorithm CryptographicAlgorithm) Validate() error {
+	return Crypto.DefaultCryptographicAlgorithmPolicy().Check(algorithm)
+}
+
+// CryptographicAlgorithmPolicy is a policy that rejects weak parameters of CryptographicAlgorithm.
+type CryptographicAlgorithmPolicy struct {
+	// MinRSABits is the minimum size of RSA keys.
+	MinRSABits int
+	// MaxRSABits is the maximum size of RSA keys. If it is 0, there is no limit.
+	MaxRSABits int
+}
+
+// DefaultCryptographicAlgorithmPolicy returns the policy that requires RSA keys of 2048-16384 bits, following NIST SP 800-131A.
+func (cryptoUtility) DefaultCryptographicAlgorithmPolicy() CryptographicAlgorithmPolicy {
+	return CryptographicAlgorithmPolicy{MinRSABits: 2048, MaxRSABits: 16384} // nolint: gomnd
+}
+
+// Check returns ErrCryptoNoSuchCryptographicAlgorithm if the algorithm is not supported, such as RSA keys that are not a multiple of 8 bits,
+// ErrCryptoWeakCryptographicAlgorithm if it is below the policy, and ErrCryptoCryptographicAlgorithmExceedsPolicy if it is above the policy.
+func (policy CryptographicAlgorithmPolicy) Check(algorithm CryptographicAlgorithm) error {
+	switch algorithm { // nolint: exhaustive
+	case CryptoECDSA256, CryptoECDSA384, CryptoEd25519, CryptoX25519:
+		return nil
+	}
+
+	bits, ok := algorithm.rsaBits()
+	if !ok {
+		return fmt.Errorf("algorithm=%s: %w", algorithm, ErrCryptoNoSuchCryptographicAlgorithm)
+	}
+
+	if bits%8 != 0 {
+		return fmt.Errorf("algorithm=%s: RSA keys must be a multiple of 8 bits: %w", algorithm, ErrCryptoNoSuchCryptographicAlgorithm)
+	}
+
+	if bits < policy.MinRSABits {
+		return fmt.Errorf("algorithm=%s: min=%d: %w", algorithm, policy.MinRSABits, ErrCryptoWeakCryptographicAlgorithm)
+	}
+
+	if policy.MaxRSABits > 0 && bits > policy.MaxRSABits {
+		return fmt.Errorf("algorithm=%s: max=%d: %w", algorithm, policy.MaxRSABits, ErrCryptoCryptographicAlgorithmExceedsPolicy)
+	}
+
+	return nil
+}
+
+// SupportedCryptographicAlgorithms returns the list of the CryptographicAlgorithm constants.
+func (cryptoUtility) SupportedCryptographicAlgorithms() []CryptographicAlgorithm {
+	return []CryptographicAlgorithm{CryptoRSA2048, CryptoRSA4096, CryptoRSA8192, CryptoECDSA256, CryptoECDSA384, CryptoEd25519, CryptoX25519}
+}
+
+// ParseCryptographicAlgorithm returns CryptographicAlgorithm from the name, and checks it by CryptographicAlgorithm.Validate.
+// It is case-insensitive, ignores "-" and "_", and accepts aliases such as "RSA-2048", "P-256", "secp384r1", "ES256" and "EdDSA".
+func (cryptoUtility) ParseCryptographicAlgorithm(name string) (CryptographicAlgorithm, error) {
+	normalized := strings.NewReplacer("-", "", "_", "").Replace(strings.ToLower(strings.TrimSpace(name)))
+
+	algorithm, ok := cryptoAlgorithmAliases[normalized]
+	if !ok {
+		algorithm = CryptographicAlgorithm(normalized)
+	}
+
+	if err := algorithm.Validate(); err != nil {
+		return "", fmt.Errorf("name=%q: %w", name, err)
+	}
+
+	return algorithm, nil
+}
+
 // GenerateKey generates a private key according to the algorithm passed.
+// The algorithm must satisfy Crypto.DefaultCryptographicAlgorithmPolicy.
 // nolint: wrapcheck
 func (cryptoUtility) GenerateKey(algorithm CryptographicAlgorithm) (crypto.PrivateKey, error) {
-	switch {
-	case algorithm == CryptoECDSA256:
+	if err := algorithm.Validate(); err != nil {
+		return nil, err
+	}
+
+	switch algorithm { // nolint: exhaustive
+	case CryptoECDSA256:
 		return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
-	case algorithm == CryptoECDSA384:
+	case CryptoECDSA384:
 		return ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
-	case algorithm == CryptoEd25519:
+	case CryptoEd25519:
 		_, privateKey, err := ed25519.GenerateKey(rand.Reader)
 		return privateKey, err
-	case strings.HasPrefix(algorithm, "rsa"):
-		bit, err := strconv.Atoi(strings.Split(algorithm, "rsa")[1])
-		if err == nil {
-			return rsa.GenerateKey(rand.Reader, bit)
-		}
+	case CryptoX25519:
+		return ecdh.X25519().GenerateKey(rand.Reader)
 	}
 
-	return nil, fmt.Errorf("algorithm=%s: %w", algorithm, ErrCryptoNoSuchCryptographicAlgorithm)
+	bits, _ := algorithm.rsaBits()
+
+	return rsa.GenerateKey(rand.Reader, bits)
 }
 
 func (cryptoUtility) GenerateKeyBytes(algorithm CryptographicAlgorithm) (privateKey []byte, err error) {
 	return Crypto.generateKeyBytes(algorithm, Crypto.GenerateKey)
 }
 
-func (cryptoUtility) generateKeyBytes(algorithm CryptographicAlgorithm, generateKeyFunc func(algorithm string) (crypto.PrivateKey, error)) (privateKey []byte, err error) {
+func (cryptoUtility) generateKeyBytes(algorithm CryptographicAlgorithm, generateKeyFunc func(algorithm CryptographicAlgorithm) (crypto.PrivateKey, error)) (privateKey []byte, err error) {
 	priv, err := generateKeyFunc(algorithm)
 	if err != nil {
 		return nil, fmt.Errorf("Crypto.GenerateKey: %w", err)
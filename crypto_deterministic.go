@@ -0,0 +1,205 @@
+package nits
+
+import (
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"math/big"
+)
+
+// cryptoDeterministicRSAExponent is the public exponent of RSA keys generated by Crypto.GenerateKeyFromReader.
+const cryptoDeterministicRSAExponent = 65537
+
+// NewDeterministicReader returns an infinite stream of bytes determined only by the seed (AES-256-CTR keyed with SHA-256 of the seed).
+//
+// WARNING: It is UNSAFE for production. Anyone who knows the seed can reproduce every byte read from it. Use it only for test fixtures and test vectors.
+func (cryptoUtility) NewDeterministicReader(seed []byte) io.Reader {
+	key := sha256.Sum256(seed)
+
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		// NOTE: aes.NewCipher fails only if the key length is invalid, and the length of a SHA-256 digest is always valid.
+		panic(fmt.Errorf("aes.NewCipher: %w", err))
+	}
+
+	return cipher.StreamReader{S: cipher.NewCTR(block, make([]byte, aes.BlockSize)), R: cryptoZeroReader{}}
+}
+
+type cryptoZeroReader struct{}
+
+func (cryptoZeroReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0
+	}
+
+	return len(p), nil
+}
+
+// GenerateKeyFromSeed generates the same private key according to the algorithm passed whenever the same seed is passed.
+//
+// WARNING: It is UNSAFE for production. The key is only as secret as the seed. Use it only for test fixtures and test vectors.
+func (cryptoUtility) GenerateKeyFromSeed(algorithm CryptographicAlgorithm, seed []byte) (crypto.PrivateKey, error) {
+	return Crypto.GenerateKeyFromReader(algorithm, Crypto.NewDeterministicReader(seed))
+}
+
+// GenerateKeyFromReader generates a private key according to the algorithm passed, reading all randomness from r.
+// Unlike Crypto.GenerateKey, the result depends only on the bytes read from r, so the same stream always produces the same key.
+//
+// WARNING: It is UNSAFE for production. Use it only for test fixtures and test vectors, and use Crypto.GenerateKey otherwise.
+func (cryptoUtility) GenerateKeyFromReader(algorithm CryptographicAlgorithm, r io.Reader) (crypto.PrivateKey, error) {
+	if err := algorithm.Validate(); err != nil {
+		return nil, err
+	}
+
+	switch algorithm { // nolint: exhaustive
+	case CryptoECDSA256:
+		return Crypto.generateECDSAKeyFromReader(elliptic.P256(), ecdh.P256(), r)
+	case CryptoECDSA384:
+		return Crypto.generateECDSAKeyFromReader(elliptic.P384(), ecdh.P384(), r)
+	case CryptoEd25519:
+		seed := make([]byte, ed25519.SeedSize)
+		if _, err := io.ReadFull(r, seed); err != nil {
+			return nil, fmt.Errorf("io.ReadFull: %w", err)
+		}
+
+		return ed25519.NewKeyFromSeed(seed), nil
+	case CryptoX25519:
+		seed := make([]byte, 32) // nolint: gomnd
+		if _, err := io.ReadFull(r, seed); err != nil {
+			return nil, fmt.Errorf("io.ReadFull: %w", err)
+		}
+
+		return ecdh.X25519().NewPrivateKey(seed) // nolint: wrapcheck
+	}
+
+	bits, _ := algorithm.rsaBits()
+
+	return Crypto.generateRSAKeyFromReader(bits, r)
+}
+
+// generateECDSAKeyFromReader derives the private scalar in the same way as FIPS 186-4 B.4.1, because ecdsa.GenerateKey does not read r deterministically.
+func (cryptoUtility) generateECDSAKeyFromReader(curve elliptic.Curve, ecdhCurve ecdh.Curve, r io.Reader) (*ecdsa.PrivateKey, error) {
+	params := curve.Params()
+	size := (params.BitSize + 7) / 8 // nolint: gomnd
+
+	b := make([]byte, size+8) // nolint: gomnd
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, fmt.Errorf("io.ReadFull: %w", err)
+	}
+
+	nMinusOne := new(big.Int).Sub(params.N, big.NewInt(1))
+	d := new(big.Int).SetBytes(b)
+	d.Mod(d, nMinusOne)
+	d.Add(d, big.NewInt(1))
+
+	privateKey, err := ecdhCurve.NewPrivateKey(d.FillBytes(make([]byte, size)))
+	if err != nil {
+		return nil, fmt.Errorf("ecdh.Curve.NewPrivateKey: %w", err)
+	}
+
+	// NOTE: The public key is an uncompressed point: 0x04 || X || Y.
+	point := privateKey.PublicKey().Bytes()
+
+	return &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{
+			Curve: curve,
+			X:     new(big.Int).SetBytes(point[1 : 1+size]),
+			Y:     new(big.Int).SetBytes(point[1+size:]),
+		},
+		D: d,
+	}, nil
+}
+
+// generateRSAKeyFromReader generates two primes from r, because rsa.GenerateKey does not read r deterministically.
+func (cryptoUtility) generateRSAKeyFromReader(bits int, r io.Reader) (*rsa.PrivateKey, error) {
+	one := big.NewInt(1)
+	e := big.NewInt(cryptoDeterministicRSAExponent)
+
+	for {
+		p, err := Crypto.generatePrimeFromReader(bits-bits/2, r) // nolint: gomnd
+		if err != nil {
+			return nil, err
+		}
+
+		q, err := Crypto.generatePrimeFromReader(bits/2, r) // nolint: gomnd
+		if err != nil {
+			return nil, err
+		}
+
+		if p.Cmp(q) == 0 {
+			continue
+		}
+
+		n := new(big.Int).Mul(p, q)
+		if n.BitLen() != bits {
+			continue
+		}
+
+		phi := new(big.Int).Mul(new(big.Int).Sub(p, one), new(big.Int).Sub(q, one))
+
+		d := new(big.Int).ModInverse(e, phi)
+		if d == nil {
+			continue
+		}
+
+		privateKey := &rsa.PrivateKey{
+			PublicKey: rsa.PublicKey{N: n, E: cryptoDeterministicRSAExponent},
+			D:         d,
+			Primes:    []*big.Int{p, q},
+		}
+
+		privateKey.Precompute()
+
+		if err := privateKey.Validate(); err != nil {
+			return nil, fmt.Errorf("(*rsa.PrivateKey).Validate: %w", err)
+		}
+
+		return privateKey, nil
+	}
+}
+
+// generatePrimeFromReader searches a prime of the bit length upward from a number read from r, with the top two bits set.
+func (cryptoUtility) generatePrimeFromReader(bits int, r io.Reader) (*big.Int, error) {
+	b := make([]byte, (bits+7)/8) // nolint: gomnd
+	two := big.NewInt(2)          // nolint: gomnd
+
+	for {
+		if _, err := io.ReadFull(r, b); err != nil {
+			return nil, fmt.Errorf("io.ReadFull: %w", err)
+		}
+
+		// NOTE: Clear the excess bits, and set the top two bits so that the product of two primes has the full bit length.
+		top := uint(bits % 8) // nolint: gomnd
+		if top == 0 {
+			top = 8
+		}
+
+		b[0] &= byte(1<<top - 1)
+
+		if top >= 2 { // nolint: gomnd
+			b[0] |= 3 << (top - 2) // nolint: gomnd
+		} else {
+			b[0] |= 1
+			b[1] |= 0x80
+		}
+
+		b[len(b)-1] |= 1
+
+		p := new(big.Int).SetBytes(b)
+		for p.BitLen() == bits {
+			if p.ProbablyPrime(20) { // nolint: gomnd
+				return p, nil
+			}
+
+			p.Add(p, two)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+// nolint: testpackage
+package nits
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestCryptoUtility_GenerateKeyFromSeed(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		algorithm CryptographicAlgorithm
+	}{
+		{"success(CryptoRSA2048)", CryptoRSA2048},
+		{"success(CryptoECDSA256)", CryptoECDSA256},
+		{"success(CryptoECDSA384)", CryptoECDSA384},
+		{"success(CryptoEd25519)", CryptoEd25519},
+		{"success(CryptoX25519)", CryptoX25519},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			first, err := Crypto.GenerateKeyFromSeed(tt.algorithm, []byte("seed"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			second, err := Crypto.GenerateKeyFromSeed(tt.algorithm, []byte("seed"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			other, err := Crypto.GenerateKeyFromSeed(tt.algorithm, []byte("other"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			firstDER, _ := x509.MarshalPKCS8PrivateKey(first)
+			secondDER, _ := x509.MarshalPKCS8PrivateKey(second)
+			otherDER, _ := x509.MarshalPKCS8PrivateKey(other)
+
+			if !bytes.Equal(firstDER, secondDER) {
+				t.Error("!bytes.Equal(firstDER, secondDER)")
+			}
+
+			if bytes.Equal(firstDER, otherDER) {
+				t.Error("bytes.Equal(firstDER, otherDER)")
+			}
+
+			if tt.algorithm == CryptoX25519 {
+				return
+			}
+
+			signature, err := Crypto.Sign(first, []byte("message"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := Crypto.Verify(second, []byte("message"), signature); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+
+	t.Run("success(golden)", func(t *testing.T) {
+		t.Parallel()
+		privateKey, err := Crypto.GenerateKeyFromSeed(CryptoEd25519, []byte("seed"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		der, _ := x509.MarshalPKCS8PrivateKey(privateKey)
+
+		const expect = "302e020100300506032b6570042204201024e03ef1672193f39622137b64561695035481b84d74f6e1066d0842a2c23e"
+		if actual := hex.EncodeToString(der); actual != expect {
+			t.Errorf("actual != expect: %s != %s", actual, expect)
+		}
+	})
+
+	t.Run("failure(ErrCryptoWeakCryptographicAlgorithm)", func(t *testing.T) {
+		t.Parallel()
+		if _, err := Crypto.GenerateKeyFromSeed("rsa512", []byte("seed")); !errors.Is(err, ErrCryptoWeakCryptographicAlgorithm) {
+			t.Errorf("err != ErrCryptoWeakCryptographicAlgorithm: %v", err)
+		}
+	})
+
+	t.Run("failure(ErrCryptoNoSuchCryptographicAlgorithm)", func(t *testing.T) {
+		t.Parallel()
+		if _, err := Crypto.GenerateKeyFromSeed("dsa", []byte("seed")); !errors.Is(err, ErrCryptoNoSuchCryptographicAlgorithm) {
+			t.Errorf("err != ErrCryptoNoSuchCryptographicAlgorithm: %v", err)
+		}
+	})
+}
+
+func TestCryptoUtility_GenerateKeyFromReader(t *testing.T) {
+	t.Parallel()
+
+	t.Run("failure(io.ErrUnexpectedEOF)", func(t *testing.T) {
+		t.Parallel()
+		for _, algorithm := range []CryptographicAlgorithm{CryptoRSA2048, CryptoECDSA256, CryptoEd25519, CryptoX25519} {
+			if _, err := Crypto.GenerateKeyFromReader(algorithm, bytes.NewReader([]byte{1, 2, 3})); !errors.Is(err, io.ErrUnexpectedEOF) {
+				t.Errorf("algorithm=%s: err != io.ErrUnexpectedEOF: %v", algorithm, err)
+			}
+		}
+	})
+}
@@ -0,0 +1,286 @@
+package nits
+
+import (
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+// ErrCryptoInvalidCiphertext invalid ciphertext.
+var ErrCryptoInvalidCiphertext = errors.New("invalid ciphertext")
+
+// CryptoEnvelopeAlgorithm is an alias of string.
+type CryptoEnvelopeAlgorithm = string
+
+const (
+	// CryptoEnvelopeRSAOAEP wraps an AES-256-GCM content key with RSA-OAEP using SHA-256.
+	CryptoEnvelopeRSAOAEP CryptoEnvelopeAlgorithm = "RSA-OAEP-256+A256GCM"
+	// CryptoEnvelopeECIESP256 derives an AES-256-GCM content key by ECDH on p-256 curve and HKDF-SHA256.
+	CryptoEnvelopeECIESP256 CryptoEnvelopeAlgorithm = "ECIES-P256+HKDF-SHA256+A256GCM"
+	// CryptoEnvelopeECIESP384 derives an AES-256-GCM content key by ECDH on p-384 curve and HKDF-SHA256.
+	CryptoEnvelopeECIESP384 CryptoEnvelopeAlgorithm = "ECIES-P384+HKDF-SHA256+A256GCM"
+	// CryptoEnvelopeECIESX25519 derives an AES-256-GCM content key by X25519 and HKDF-SHA256.
+	CryptoEnvelopeECIESX25519 CryptoEnvelopeAlgorithm = "ECIES-X25519+HKDF-SHA256+A256GCM"
+)
+
+const (
+	cryptoEnvelopeVersion = 1
+	cryptoContentKeySize  = 32
+)
+
+// Encrypt encrypts the plaintext to the public key, and returns a self-describing envelope:
+//
+//	version (1 byte) || len(algorithm) (1 byte) || algorithm || len(encapsulated key) (2 bytes, big endian) || encapsulated key || nonce || AES-256-GCM ciphertext
+//
+// The encapsulated key is the RSA-OAEP-wrapped content key for RSA keys, and the ephemeral public key for ECDSA and X25519 keys.
+// The header before the nonce is authenticated as additional data.
+func (cryptoUtility) Encrypt(publicKey crypto.PublicKey, plaintext []byte) (envelope []byte, err error) {
+	algorithm, contentKey, encapsulatedKey, err := Crypto.encapsulate(publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	header := Crypto.marshalEnvelopeHeader(algorithm, encapsulatedKey)
+
+	aead, err := Crypto.newAESGCM(contentKey)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("rand.Reader: %w", err)
+	}
+
+	envelope = append(header, nonce...) // nolint: gocritic
+	envelope = aead.Seal(envelope, nonce, plaintext, header)
+
+	return envelope, nil
+}
+
+// encapsulate generates a content key for the public key, and returns it with its encapsulated form.
+func (cryptoUtility) encapsulate(publicKey crypto.PublicKey) (algorithm CryptoEnvelopeAlgorithm, contentKey, encapsulatedKey []byte, err error) {
+	if priv, ok := publicKey.(interface{ Public() crypto.PublicKey }); ok {
+		publicKey = priv.Public()
+	}
+
+	var recipient *ecdh.PublicKey
+
+	switch pub := publicKey.(type) {
+	case *rsa.PublicKey:
+		contentKey = make([]byte, cryptoContentKeySize)
+		if _, err := io.ReadFull(rand.Reader, contentKey); err != nil {
+			return "", nil, nil, fmt.Errorf("rand.Reader: %w", err)
+		}
+
+		encapsulatedKey, err = rsa.EncryptOAEP(sha256.New(), rand.Reader, pub, contentKey, []byte(CryptoEnvelopeRSAOAEP))
+		if err != nil {
+			return "", nil, nil, fmt.Errorf("rsa.EncryptOAEP: %w", err)
+		}
+
+		return CryptoEnvelopeRSAOAEP, contentKey, encapsulatedKey, nil
+	case *ecdsa.PublicKey:
+		if recipient, err = pub.ECDH(); err != nil {
+			return "", nil, nil, fmt.Errorf("(*ecdsa.PublicKey).ECDH: %v: %w", err, ErrCryptoUnsupportedKeyType)
+		}
+	case *ecdh.PublicKey:
+		recipient = pub
+	default:
+		return "", nil, nil, fmt.Errorf("%T: %w", publicKey, ErrCryptoUnsupportedKeyType)
+	}
+
+	if algorithm, err = Crypto.envelopeAlgorithmOf(recipient.Curve()); err != nil {
+		return "", nil, nil, err
+	}
+
+	ephemeral, err := recipient.Curve().GenerateKey(rand.Reader)
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("(ecdh.Curve).GenerateKey: %w", err)
+	}
+
+	sharedSecret, err := ephemeral.ECDH(recipient)
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("(*ecdh.PrivateKey).ECDH: %w", err)
+	}
+
+	encapsulatedKey = ephemeral.PublicKey().Bytes()
+	if contentKey, err = Crypto.deriveContentKey(algorithm, sharedSecret, encapsulatedKey, recipient.Bytes()); err != nil {
+		return "", nil, nil, err
+	}
+
+	return algorithm, contentKey, encapsulatedKey, nil
+}
+
+// Decrypt decrypts the envelope returned by Crypto.Encrypt with the private key.
+func (cryptoUtility) Decrypt(privateKey crypto.PrivateKey, envelope []byte) (plaintext []byte, err error) {
+	algorithm, encapsulatedKey, body, err := Crypto.unmarshalEnvelope(envelope)
+	if err != nil {
+		return nil, err
+	}
+
+	header := envelope[:len(envelope)-len(body)]
+
+	var contentKey []byte
+
+	switch priv := privateKey.(type) {
+	case *rsa.PrivateKey:
+		if algorithm != CryptoEnvelopeRSAOAEP {
+			return nil, fmt.Errorf("algorithm=%s key=%T: %w", algorithm, privateKey, ErrCryptoInvalidCiphertext)
+		}
+
+		contentKey, err = rsa.DecryptOAEP(sha256.New(), nil, priv, encapsulatedKey, []byte(algorithm))
+		if err != nil {
+			return nil, fmt.Errorf("rsa.DecryptOAEP: %v: %w", err, ErrCryptoInvalidCiphertext)
+		}
+	case *ecdsa.PrivateKey:
+		recipient, err := priv.ECDH()
+		if err != nil {
+			return nil, fmt.Errorf("(*ecdsa.PrivateKey).ECDH: %v: %w", err, ErrCryptoUnsupportedKeyType)
+		}
+
+		if contentKey, err = Crypto.decapsulateECDH(algorithm, recipient, encapsulatedKey); err != nil {
+			return nil, err
+		}
+	case *ecdh.PrivateKey:
+		if contentKey, err = Crypto.decapsulateECDH(algorithm, priv, encapsulatedKey); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("%T: %w", privateKey, ErrCryptoUnsupportedKeyType)
+	}
+
+	aead, err := Crypto.newAESGCM(contentKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(body) < aead.NonceSize() {
+		return nil, fmt.Errorf("nonce: too short: %w", ErrCryptoInvalidCiphertext)
+	}
+
+	plaintext, err = aead.Open(nil, body[:aead.NonceSize()], body[aead.NonceSize():], header)
+	if err != nil {
+		return nil, fmt.Errorf("(cipher.AEAD).Open: %v: %w", err, ErrCryptoInvalidCiphertext)
+	}
+
+	return plaintext, nil
+}
+
+func (cryptoUtility) decapsulateECDH(algorithm CryptoEnvelopeAlgorithm, recipient *ecdh.PrivateKey, encapsulatedKey []byte) (contentKey []byte, err error) {
+	expected, err := Crypto.envelopeAlgorithmOf(recipient.Curve())
+	if err != nil {
+		return nil, err
+	}
+
+	if algorithm != expected {
+		return nil, fmt.Errorf("algorithm=%s curve=%v: %w", algorithm, recipient.Curve(), ErrCryptoInvalidCiphertext)
+	}
+
+	ephemeral, err := recipient.Curve().NewPublicKey(encapsulatedKey)
+	if err != nil {
+		return nil, fmt.Errorf("(ecdh.Curve).NewPublicKey: %v: %w", err, ErrCryptoInvalidCiphertext)
+	}
+
+	sharedSecret, err := recipient.ECDH(ephemeral)
+	if err != nil {
+		return nil, fmt.Errorf("(*ecdh.PrivateKey).ECDH: %v: %w", err, ErrCryptoInvalidCiphertext)
+	}
+
+	return Crypto.deriveContentKey(algorithm, sharedSecret, encapsulatedKey, recipient.PublicKey().Bytes())
+}
+
+func (cryptoUtility) envelopeAlgorithmOf(curve ecdh.Curve) (CryptoEnvelopeAlgorithm, error) {
+	switch curve {
+	case ecdh.P256():
+		return CryptoEnvelopeECIESP256, nil
+	case ecdh.P384():
+		return CryptoEnvelopeECIESP384, nil
+	case ecdh.X25519():
+		return CryptoEnvelopeECIESX25519, nil
+	}
+
+	return "", fmt.Errorf("curve=%v: %w", curve, ErrCryptoUnsupportedKeyType)
+}
+
+// hkdfSHA256 derives a key of the length from the secret by HKDF-SHA256.
+func (cryptoUtility) hkdfSHA256(secret, salt []byte, info string, length int) ([]byte, error) {
+	key := make([]byte, length)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, secret, salt, []byte(info)), key); err != nil {
+		return nil, fmt.Errorf("hkdf.New: %w", err)
+	}
+
+	return key, nil
+}
+
+// deriveContentKey derives the content key by HKDF-SHA256 from the ECDH shared secret,
+// salted with the ephemeral and the recipient public keys, and bound to the algorithm.
+func (cryptoUtility) deriveContentKey(algorithm CryptoEnvelopeAlgorithm, sharedSecret, ephemeralPublicKey, recipientPublicKey []byte) ([]byte, error) {
+	salt := append(append([]byte{}, ephemeralPublicKey...), recipientPublicKey...)
+
+	contentKey, err := Crypto.hkdfSHA256(sharedSecret, salt, algorithm, cryptoContentKeySize)
+	if err != nil {
+		return nil, err
+	}
+
+	return contentKey, nil
+}
+
+func (cryptoUtility) newAESGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("aes.NewCipher: %w", err)
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("cipher.NewGCM: %w", err)
+	}
+
+	return aead, nil
+}
+
+func (cryptoUtility) marshalEnvelopeHeader(algorithm CryptoEnvelopeAlgorithm, encapsulatedKey []byte) []byte {
+	header := make([]byte, 0, 4+len(algorithm)+len(encapsulatedKey)) // nolint: gomnd
+	header = append(header, cryptoEnvelopeVersion, byte(len(algorithm)))
+	header = append(header, algorithm...)
+	header = binary.BigEndian.AppendUint16(header, uint16(len(encapsulatedKey)))
+	header = append(header, encapsulatedKey...)
+
+	return header
+}
+
+func (cryptoUtility) unmarshalEnvelope(envelope []byte) (algorithm CryptoEnvelopeAlgorithm, encapsulatedKey, body []byte, err error) {
+	if len(envelope) < 2 || envelope[0] != cryptoEnvelopeVersion { // nolint: gomnd
+		return "", nil, nil, fmt.Errorf("version: %w", ErrCryptoInvalidCiphertext)
+	}
+
+	rest := envelope[2:]
+	if algorithmLength := int(envelope[1]); len(rest) >= algorithmLength {
+		algorithm, rest = string(rest[:algorithmLength]), rest[algorithmLength:]
+	} else {
+		return "", nil, nil, fmt.Errorf("algorithm: too short: %w", ErrCryptoInvalidCiphertext)
+	}
+
+	if len(rest) < 2 { // nolint: gomnd
+		return "", nil, nil, fmt.Errorf("encapsulated key: too short: %w", ErrCryptoInvalidCiphertext)
+	}
+
+	if keyLength := int(binary.BigEndian.Uint16(rest)); len(rest[2:]) >= keyLength {
+		encapsulatedKey, body = rest[2:2+keyLength], rest[2+keyLength:]
+	} else {
+		return "", nil, nil, fmt.Errorf("encapsulated key: too short: %w", ErrCryptoInvalidCiphertext)
+	}
+
+	return algorithm, encapsulatedKey, body, nil
+}
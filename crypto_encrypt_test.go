@@ -0,0 +1,102 @@
+// nolint: testpackage
+package nits
+
+import (
+	"crypto/dsa" // nolint: staticcheck
+	"errors"
+	"testing"
+
+	"github.com/nitpickers/nits.go/nitstest"
+)
+
+func Test_cryptoUtility_Encrypt_Decrypt(t *testing.T) {
+	t.Parallel()
+
+	plaintext := []byte("plaintext")
+	tests := []struct {
+		name      string
+		algorithm CryptographicAlgorithm
+		envelope  CryptoEnvelopeAlgorithm
+	}{
+		{"success(CryptoRSA2048)", CryptoRSA2048, CryptoEnvelopeRSAOAEP},
+		{"success(CryptoECDSA256)", CryptoECDSA256, CryptoEnvelopeECIESP256},
+		{"success(CryptoECDSA384)", CryptoECDSA384, CryptoEnvelopeECIESP384},
+		{"success(CryptoX25519)", CryptoX25519, CryptoEnvelopeECIESX25519},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			privateKey := Crypto.MustGenerateKey(Crypto.GenerateKey(tt.algorithm))
+			envelope, err := Crypto.Encrypt(privateKey, plaintext)
+			if err != nil {
+				t.Fatalf("Crypto.Encrypt: %v", err)
+			}
+			algorithm, _, _, err := Crypto.unmarshalEnvelope(envelope)
+			if err != nil {
+				t.Fatalf("Crypto.unmarshalEnvelope: %v", err)
+			}
+			nitstest.FailIfNotEqual(t, tt.envelope, algorithm)
+
+			actual, err := Crypto.Decrypt(privateKey, envelope)
+			if err != nil {
+				t.Fatalf("Crypto.Decrypt: %v", err)
+			}
+			nitstest.FailIfNotBytesEqual(t, plaintext, actual)
+
+			for i := range envelope {
+				tampered := append([]byte{}, envelope...)
+				tampered[i] ^= 0x01
+				if _, err := Crypto.Decrypt(privateKey, tampered); err == nil {
+					t.Fatalf("tampered[%d]: err == nil", i)
+				}
+			}
+		})
+	}
+}
+
+func Test_cryptoUtility_Encrypt(t *testing.T) {
+	t.Parallel()
+
+	t.Run("failure(Ed25519)", func(t *testing.T) {
+		t.Parallel()
+		privateKey := Crypto.MustGenerateKey(Crypto.GenerateKey(CryptoEd25519))
+		if _, err := Crypto.Encrypt(privateKey, nil); !errors.Is(err, ErrCryptoUnsupportedKeyType) {
+			t.Errorf("err != ErrCryptoUnsupportedKeyType: %v", err)
+		}
+	})
+}
+
+func Test_cryptoUtility_Decrypt(t *testing.T) {
+	t.Parallel()
+
+	ecdsaKey := Crypto.MustGenerateKey(Crypto.GenerateKey(CryptoECDSA256))
+	x25519Key := Crypto.MustGenerateKey(Crypto.GenerateKey(CryptoX25519))
+	envelope, err := Crypto.Encrypt(ecdsaKey, []byte("plaintext"))
+	if err != nil {
+		t.Fatalf("Crypto.Encrypt: %v", err)
+	}
+	tests := []struct {
+		name       string
+		privateKey interface{}
+		envelope   []byte
+		wantErr    error
+	}{
+		{"failure(version)", ecdsaKey, []byte{0}, ErrCryptoInvalidCiphertext},
+		{"failure(algorithm)", ecdsaKey, []byte{1, 10, 'a'}, ErrCryptoInvalidCiphertext},
+		{"failure(encapsulated key)", ecdsaKey, []byte{1, 1, 'a', 0}, ErrCryptoInvalidCiphertext},
+		{"failure(encapsulated key,length)", ecdsaKey, []byte{1, 1, 'a', 0, 2, 0}, ErrCryptoInvalidCiphertext},
+		{"failure(nonce)", ecdsaKey, append(append([]byte{}, envelope[:len(envelope)-len("plaintext")-32]...), 0), ErrCryptoInvalidCiphertext},
+		{"failure(KeyMismatch)", x25519Key, envelope, ErrCryptoInvalidCiphertext},
+		{"failure(ErrCryptoUnsupportedKeyType)", &dsa.PrivateKey{}, envelope, ErrCryptoUnsupportedKeyType},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if _, err := Crypto.Decrypt(tt.privateKey, tt.envelope); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Crypto.Decrypt() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
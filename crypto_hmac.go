@@ -0,0 +1,229 @@
+package nits
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrCryptoInvalidToken invalid token.
+	ErrCryptoInvalidToken = errors.New("invalid token")
+
+	// ErrCryptoTokenHasExpired token has expired.
+	ErrCryptoTokenHasExpired = errors.New("token has expired")
+)
+
+const (
+	// HMACSignerURLExpiresParameter is the query parameter of the expiry of signed URLs in seconds since the epoch.
+	HMACSignerURLExpiresParameter = "expires"
+	// HMACSignerURLKeyIDParameter is the query parameter of the key ID of signed URLs.
+	HMACSignerURLKeyIDParameter = "kid"
+	// HMACSignerURLSignatureParameter is the query parameter of the signature of signed URLs.
+	HMACSignerURLSignatureParameter = "signature"
+
+	cryptoHMACTokenVersion = 1
+	cryptoHMACInfo         = "nits hmac-sha256 token key"
+	cryptoHMACExpiresSize  = 8
+)
+
+// HMACSigner signs and verifies expiring opaque tokens and URLs with HMAC-SHA256.
+// It signs with the primary key of the SymmetricKeyRing and verifies with the key recorded in the token,
+// so the keys can be rotated by SymmetricKeyRing.Rotate. The HMAC keys are derived from the ring keys with HKDF,
+// so the same ring can also be used for SymmetricKeyRing.Seal without reusing the keys across algorithms.
+type HMACSigner struct {
+	keys *SymmetricKeyRing
+	now  func() time.Time
+}
+
+// HMACSignerOption is an option for Crypto.NewHMACSigner.
+type HMACSignerOption func(*HMACSigner)
+
+// WithHMACSignerClock replaces time.Now used to check the expiry.
+func (cryptoUtility) WithHMACSignerClock(now func() time.Time) HMACSignerOption {
+	return func(s *HMACSigner) { s.now = now }
+}
+
+// NewHMACSigner returns *HMACSigner that uses the keys.
+func (cryptoUtility) NewHMACSigner(keys *SymmetricKeyRing, opts ...HMACSignerOption) *HMACSigner {
+	s := &HMACSigner{keys: keys, now: time.Now}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+func (*HMACSigner) mac(key []byte, data ...[]byte) ([]byte, error) {
+	macKey, err := Crypto.hkdfSHA256(key, nil, cryptoHMACInfo, sha256.Size)
+	if err != nil {
+		return nil, err
+	}
+
+	h := hmac.New(sha256.New, macKey)
+	for _, d := range data {
+		h.Write(d)
+	}
+
+	return h.Sum(nil), nil
+}
+
+// Sign returns a URL-safe token that contains the payload and the expiry, signed with the primary key:
+//
+//	base64url(version (1 byte) || len(key ID) (1 byte) || key ID || expiry (8 bytes) || payload) "." base64url(HMAC-SHA256)
+//
+// The payload is not encrypted.
+func (s *HMACSigner) Sign(payload []byte, expiresAt time.Time) (string, error) {
+	id, key, err := s.keys.primaryKey()
+	if err != nil {
+		return "", err
+	}
+
+	data := append([]byte{cryptoHMACTokenVersion, byte(len(id))}, id...)
+	data = binary.BigEndian.AppendUint64(data, uint64(expiresAt.Unix()))
+	data = append(data, payload...)
+
+	mac, err := s.mac(key, data)
+	if err != nil {
+		return "", err
+	}
+
+	encode := base64.RawURLEncoding.EncodeToString
+
+	return encode(data) + "." + encode(mac), nil
+}
+
+// Verify verifies the token returned by Sign in constant time, and returns its payload.
+func (s *HMACSigner) Verify(token string) (payload []byte, err error) {
+	encodedData, encodedMAC, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, fmt.Errorf("separator: %w", ErrCryptoInvalidToken)
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(encodedData)
+	if err != nil {
+		return nil, fmt.Errorf("base64.RawURLEncoding.DecodeString: %v: %w", err, ErrCryptoInvalidToken)
+	}
+
+	mac, err := base64.RawURLEncoding.DecodeString(encodedMAC)
+	if err != nil {
+		return nil, fmt.Errorf("base64.RawURLEncoding.DecodeString: %v: %w", err, ErrCryptoInvalidToken)
+	}
+
+	id, rest, err := Crypto.unmarshalKeyIDHeader(data, cryptoHMACTokenVersion)
+	if err != nil || len(rest) < cryptoHMACExpiresSize {
+		return nil, fmt.Errorf("header: %v: %w", err, ErrCryptoInvalidToken)
+	}
+
+	key, err := s.keys.key(id)
+	if err != nil {
+		return nil, err
+	}
+
+	expected, err := s.mac(key, data)
+	if err != nil {
+		return nil, err
+	}
+
+	if !hmac.Equal(mac, expected) {
+		return nil, fmt.Errorf("signature: %w", ErrCryptoInvalidToken)
+	}
+
+	expiresAt := time.Unix(int64(binary.BigEndian.Uint64(rest)), 0)
+	if !s.now().Before(expiresAt) {
+		return nil, fmt.Errorf("expires=%s: %w", expiresAt, ErrCryptoTokenHasExpired)
+	}
+
+	return rest[cryptoHMACExpiresSize:], nil
+}
+
+// SignURL returns the URL with the expires, kid and signature query parameters added.
+// The signature covers the path and the whole query except the signature parameter, so neither can be changed.
+func (s *HMACSigner) SignURL(rawURL string, expiresAt time.Time) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("url.Parse: %w", err)
+	}
+
+	id, key, err := s.keys.primaryKey()
+	if err != nil {
+		return "", err
+	}
+
+	query := u.Query()
+	query.Del(HMACSignerURLSignatureParameter)
+	query.Set(HMACSignerURLExpiresParameter, strconv.FormatInt(expiresAt.Unix(), 10))
+	query.Set(HMACSignerURLKeyIDParameter, id)
+
+	mac, err := s.mac(key, []byte(u.EscapedPath()), []byte{'?'}, []byte(query.Encode()))
+	if err != nil {
+		return "", err
+	}
+
+	query.Set(HMACSignerURLSignatureParameter, base64.RawURLEncoding.EncodeToString(mac))
+	u.RawQuery = query.Encode()
+
+	return u.String(), nil
+}
+
+// VerifyURL verifies the URL returned by SignURL. Only the path and the query are verified, so the scheme and the host may differ,
+// which allows the URL to be verified with (*http.Request).URL on the server side.
+func (s *HMACSigner) VerifyURL(u *url.URL) error {
+	query := u.Query()
+
+	mac, err := base64.RawURLEncoding.DecodeString(query.Get(HMACSignerURLSignatureParameter))
+	if err != nil || len(mac) == 0 {
+		return fmt.Errorf("%s: %v: %w", HMACSignerURLSignatureParameter, err, ErrCryptoInvalidToken)
+	}
+
+	query.Del(HMACSignerURLSignatureParameter)
+
+	key, err := s.keys.key(query.Get(HMACSignerURLKeyIDParameter))
+	if err != nil {
+		return err
+	}
+
+	expected, err := s.mac(key, []byte(u.EscapedPath()), []byte{'?'}, []byte(query.Encode()))
+	if err != nil {
+		return err
+	}
+
+	if !hmac.Equal(mac, expected) {
+		return fmt.Errorf("signature: %w", ErrCryptoInvalidToken)
+	}
+
+	expires, err := strconv.ParseInt(query.Get(HMACSignerURLExpiresParameter), 10, 64)
+	if err != nil {
+		return fmt.Errorf("%s: strconv.ParseInt: %v: %w", HMACSignerURLExpiresParameter, err, ErrCryptoInvalidToken)
+	}
+
+	if expiresAt := time.Unix(expires, 0); !s.now().Before(expiresAt) {
+		return fmt.Errorf("expires=%s: %w", expiresAt, ErrCryptoTokenHasExpired)
+	}
+
+	return nil
+}
+
+// VerifyURLMiddleware returns a middleware that responds 403 Forbidden unless the request URL has been signed by SignURL.
+// It can be combined with other middlewares by HTTP.AddMiddlewares.
+func (s *HMACSigner) VerifyURLMiddleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err := s.VerifyURL(r.URL); err != nil {
+				http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,166 @@
+// nolint: testpackage
+package nits
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHMACSigner_Sign(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	ring := testNewSymmetricKeyRing(t, "old")
+	signer := Crypto.NewHMACSigner(ring, Crypto.WithHMACSignerClock(func() time.Time { return now }))
+
+	token, err := signer.Sign([]byte("user=1"), now.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("success(rotation)", func(t *testing.T) {
+		t.Parallel()
+		ring := testNewSymmetricKeyRing(t, "old")
+		signer := Crypto.NewHMACSigner(ring, Crypto.WithHMACSignerClock(func() time.Time { return now }))
+
+		token, err := signer.Sign([]byte("user=1"), now.Add(time.Hour))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if strings.ContainsAny(token, "+/=") {
+			t.Errorf("token is not URL-safe: %s", token)
+		}
+
+		if err := ring.Rotate("new"); err != nil {
+			t.Fatal(err)
+		}
+
+		payload, err := signer.Verify(token)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(payload) != "user=1" {
+			t.Errorf("unexpected payload: %s", payload)
+		}
+	})
+
+	t.Run("failure(ErrCryptoTokenHasExpired)", func(t *testing.T) {
+		t.Parallel()
+		expired := Crypto.NewHMACSigner(ring, Crypto.WithHMACSignerClock(func() time.Time { return now.Add(time.Hour) }))
+		if _, err := expired.Verify(token); !errors.Is(err, ErrCryptoTokenHasExpired) {
+			t.Errorf("err != ErrCryptoTokenHasExpired: %v", err)
+		}
+	})
+
+	t.Run("failure(ErrCryptoInvalidToken)", func(t *testing.T) {
+		t.Parallel()
+		data, mac, _ := strings.Cut(token, ".")
+		other, _ := signer.Sign([]byte("user=2"), now.Add(time.Hour))
+		_, otherMAC, _ := strings.Cut(other, ".")
+
+		for _, tampered := range []string{"", data, data + "." + otherMAC, data + ".!", "!." + mac, "AA." + mac} {
+			if _, err := signer.Verify(tampered); !errors.Is(err, ErrCryptoInvalidToken) {
+				t.Errorf("token=%s: err != ErrCryptoInvalidToken: %v", tampered, err)
+			}
+		}
+	})
+
+	t.Run("failure(ErrCryptoKeyNotFound)", func(t *testing.T) {
+		t.Parallel()
+		ring := testNewSymmetricKeyRing(t, "old")
+		signer := Crypto.NewHMACSigner(ring, Crypto.WithHMACSignerClock(func() time.Time { return now }))
+
+		token, err := signer.Sign([]byte("user=1"), now.Add(time.Hour))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ring.Remove("old")
+		if _, err := signer.Verify(token); !errors.Is(err, ErrCryptoKeyNotFound) {
+			t.Errorf("err != ErrCryptoKeyNotFound: %v", err)
+		}
+	})
+
+	t.Run("failure(ErrCryptoNoPrimaryKey)", func(t *testing.T) {
+		t.Parallel()
+		if _, err := Crypto.NewHMACSigner(Crypto.NewSymmetricKeyRing()).Sign(nil, now); !errors.Is(err, ErrCryptoNoPrimaryKey) {
+			t.Errorf("err != ErrCryptoNoPrimaryKey: %v", err)
+		}
+	})
+}
+
+func TestHMACSigner_SignURL(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	signer := Crypto.NewHMACSigner(testNewSymmetricKeyRing(t, "key"), Crypto.WithHMACSignerClock(func() time.Time { return now }))
+
+	signed, err := signer.SignURL("https://example.com/files/report%20a.pdf?b=2&a=1", now.Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("success()", func(t *testing.T) {
+		t.Parallel()
+		u, _ := url.Parse(signed)
+		if err := signer.VerifyURL(u); err != nil {
+			t.Error(err)
+		}
+
+		if q := u.Query(); q.Get("a") != "1" || q.Get(HMACSignerURLKeyIDParameter) != "key" || q.Get(HMACSignerURLExpiresParameter) != "1704067260" {
+			t.Errorf("unexpected query: %s", u.RawQuery)
+		}
+	})
+
+	t.Run("failure(ErrCryptoInvalidToken)", func(t *testing.T) {
+		t.Parallel()
+		for _, tampered := range []string{
+			strings.Replace(signed, "a=1", "a=2", 1),
+			strings.Replace(signed, "/files/", "/other/", 1),
+			strings.Replace(signed, "expires=1704067260", "expires=1704067261", 1),
+			signed + "&c=3",
+			strings.Split(signed, "signature=")[0],
+		} {
+			u, _ := url.Parse(tampered)
+			if err := signer.VerifyURL(u); !errors.Is(err, ErrCryptoInvalidToken) {
+				t.Errorf("url=%s: err != ErrCryptoInvalidToken: %v", tampered, err)
+			}
+		}
+	})
+
+	t.Run("success(VerifyURLMiddleware)", func(t *testing.T) {
+		t.Parallel()
+		handler := HTTP.AddMiddlewares(signer.VerifyURLMiddleware())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		}))
+
+		for target, expect := range map[string]int{
+			strings.TrimPrefix(signed, "https://example.com"): http.StatusNoContent,
+			"/files/report%20a.pdf?a=1&b=2":                   http.StatusForbidden,
+		} {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+
+			if w.Code != expect {
+				t.Errorf("target=%s: code != %d: %d", target, expect, w.Code)
+			}
+		}
+	})
+
+	t.Run("failure(ErrCryptoTokenHasExpired)", func(t *testing.T) {
+		t.Parallel()
+		u, _ := url.Parse(signed)
+		expired := Crypto.NewHMACSigner(signer.keys, Crypto.WithHMACSignerClock(func() time.Time { return now.Add(time.Minute) }))
+
+		if err := expired.VerifyURL(u); !errors.Is(err, ErrCryptoTokenHasExpired) {
+			t.Errorf("err != ErrCryptoTokenHasExpired: %v", err)
+		}
+	})
+}
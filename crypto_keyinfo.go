@@ -0,0 +1,118 @@
+package nits
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	// CryptoKeyTypeRSA is the KeyType of RSA keys.
+	CryptoKeyTypeRSA = "RSA"
+	// CryptoKeyTypeECDSA is the KeyType of ECDSA keys.
+	CryptoKeyTypeECDSA = "ECDSA"
+	// CryptoKeyTypeEd25519 is the KeyType of Ed25519 keys.
+	CryptoKeyTypeEd25519 = "Ed25519"
+	// CryptoKeyTypeX25519 is the KeyType of X25519 keys.
+	CryptoKeyTypeX25519 = "X25519"
+)
+
+// KeyInfo describes a key without its secret, so that it can be logged to identify which key is in use.
+type KeyInfo struct {
+	// Algorithm is the CryptographicAlgorithm that generates the same kind of key.
+	// It is empty if there is none, such as for ECDSA keys on P-521.
+	Algorithm CryptographicAlgorithm `json:"algorithm,omitempty"`
+	// KeyType is one of CryptoKeyTypeRSA, CryptoKeyTypeECDSA, CryptoKeyTypeEd25519 and CryptoKeyTypeX25519.
+	KeyType string `json:"keyType"`
+	// BitSize is the size of the modulus for RSA, and the size of the curve otherwise.
+	BitSize int `json:"bitSize"`
+	// Curve is the name of the curve such as "P-256". It is empty for RSA.
+	Curve string `json:"curve,omitempty"`
+	// Private reports whether the key passed is a private key.
+	Private bool `json:"private"`
+	// PublicKey is the public key.
+	PublicKey crypto.PublicKey `json:"-"`
+	// SPKISHA256Hex is the SHA-256 of the DER-encoded SubjectPublicKeyInfo in lowercase hex.
+	SPKISHA256Hex string `json:"spkiSHA256Hex"`
+	// SPKISHA256Base64 is the SHA-256 of the DER-encoded SubjectPublicKeyInfo in standard base64, as used by HPKP pins.
+	SPKISHA256Base64 string `json:"spkiSHA256Base64"`
+	// SSHFingerprint is the OpenSSH-style fingerprint such as "SHA256:...". It is empty for X25519, which OpenSSH does not support.
+	SSHFingerprint string `json:"sshFingerprint,omitempty"`
+}
+
+// KeyInfo returns *KeyInfo of the key. The key may be crypto.PrivateKey or crypto.PublicKey.
+func (cryptoUtility) KeyInfo(key interface{}) (*KeyInfo, error) {
+	info := new(KeyInfo)
+
+	if priv, ok := key.(interface{ Public() crypto.PublicKey }); ok {
+		info.Private = true
+		key = priv.Public()
+	}
+
+	switch pub := key.(type) {
+	case *rsa.PublicKey:
+		info.KeyType = CryptoKeyTypeRSA
+		info.BitSize = pub.N.BitLen()
+		info.Algorithm = CryptographicAlgorithm(cryptoRSAPrefix + strconv.Itoa(info.BitSize))
+	case *ecdsa.PublicKey:
+		info.KeyType = CryptoKeyTypeECDSA
+		info.BitSize = pub.Curve.Params().BitSize
+		info.Curve = pub.Curve.Params().Name
+
+		switch pub.Curve {
+		case elliptic.P256():
+			info.Algorithm = CryptoECDSA256
+		case elliptic.P384():
+			info.Algorithm = CryptoECDSA384
+		}
+	case ed25519.PublicKey:
+		info.KeyType = CryptoKeyTypeEd25519
+		info.BitSize = 256
+		info.Curve = JWKCurveEd25519
+		info.Algorithm = CryptoEd25519
+	case *ecdh.PublicKey:
+		if pub.Curve() != ecdh.X25519() {
+			return nil, fmt.Errorf("curve=%s: %w", pub.Curve(), ErrCryptoUnsupportedKeyType)
+		}
+
+		info.KeyType = CryptoKeyTypeX25519
+		info.BitSize = 256
+		info.Curve = "X25519"
+		info.Algorithm = CryptoX25519
+	default:
+		return nil, fmt.Errorf("%T: %w", key, ErrCryptoUnsupportedKeyType)
+	}
+
+	info.PublicKey = key
+
+	spki, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("x509.MarshalPKIXPublicKey: %w", err)
+	}
+
+	sum := sha256.Sum256(spki)
+	info.SPKISHA256Hex = hex.EncodeToString(sum[:])
+	info.SPKISHA256Base64 = base64.StdEncoding.EncodeToString(sum[:])
+
+	if info.KeyType != CryptoKeyTypeX25519 {
+		sshPublicKey, err := ssh.NewPublicKey(key)
+		if err != nil {
+			return nil, fmt.Errorf("ssh.NewPublicKey: %w", err)
+		}
+
+		info.SSHFingerprint = ssh.FingerprintSHA256(sshPublicKey)
+	}
+
+	return info, nil
+}
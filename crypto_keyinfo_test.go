@@ -0,0 +1,112 @@
+// nolint: testpackage
+package nits
+
+import (
+	"crypto"
+	"crypto/dsa" // nolint: staticcheck
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"testing"
+)
+
+func TestCryptoUtility_KeyInfo(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		algorithm CryptographicAlgorithm
+		keyType   string
+		bitSize   int
+		curve     string
+		ssh       bool
+	}{
+		{"success(CryptoRSA2048)", CryptoRSA2048, CryptoKeyTypeRSA, 2048, "", true},
+		{"success(CryptoECDSA256)", CryptoECDSA256, CryptoKeyTypeECDSA, 256, "P-256", true},
+		{"success(CryptoECDSA384)", CryptoECDSA384, CryptoKeyTypeECDSA, 384, "P-384", true},
+		{"success(CryptoEd25519)", CryptoEd25519, CryptoKeyTypeEd25519, 256, "Ed25519", true},
+		{"success(CryptoX25519)", CryptoX25519, CryptoKeyTypeX25519, 256, "X25519", false},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			privateKey, err := Crypto.GenerateKeyFromSeed(tt.algorithm, []byte("seed"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			info, err := Crypto.KeyInfo(privateKey)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if info.Algorithm != tt.algorithm || info.KeyType != tt.keyType || info.BitSize != tt.bitSize || info.Curve != tt.curve || !info.Private {
+				t.Errorf("unexpected info: %+v", info)
+			}
+
+			if len(info.SPKISHA256Hex) != 64 || len(info.SPKISHA256Base64) != 44 || (info.SSHFingerprint != "") != tt.ssh {
+				t.Errorf("unexpected fingerprints: %+v", info)
+			}
+
+			publicInfo, err := Crypto.KeyInfo(privateKey.(interface{ Public() crypto.PublicKey }).Public())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if publicInfo.Private || publicInfo.SPKISHA256Hex != info.SPKISHA256Hex || publicInfo.SSHFingerprint != info.SSHFingerprint {
+				t.Errorf("public key info does not match: %+v != %+v", publicInfo, info)
+			}
+		})
+	}
+
+	t.Run("success(golden)", func(t *testing.T) {
+		t.Parallel()
+		privateKey, err := Crypto.GenerateKeyFromSeed(CryptoEd25519, []byte("seed"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		info, err := Crypto.KeyInfo(privateKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if expect := "fd6101019dcc27c3c923f789a90c7970fc6cc1d872e190459ff1b19104a40de5"; info.SPKISHA256Hex != expect {
+			t.Errorf("info.SPKISHA256Hex != expect: %s != %s", info.SPKISHA256Hex, expect)
+		}
+
+		if expect := "/WEBAZ3MJ8PJI/eJqQx5cPxswdhy4ZBFn/GxkQSkDeU="; info.SPKISHA256Base64 != expect {
+			t.Errorf("info.SPKISHA256Base64 != expect: %s != %s", info.SPKISHA256Base64, expect)
+		}
+
+		if expect := "SHA256:NoY+pKVK3X/mtKnWt2M8NfiQVrcIan6pIH71ttql9IA"; info.SSHFingerprint != expect {
+			t.Errorf("info.SSHFingerprint != expect: %s != %s", info.SSHFingerprint, expect)
+		}
+	})
+
+	t.Run("success(P-521)", func(t *testing.T) {
+		t.Parallel()
+		privateKey, err := ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		info, err := Crypto.KeyInfo(privateKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if info.Algorithm != "" || info.KeyType != CryptoKeyTypeECDSA || info.BitSize != 521 || info.Curve != "P-521" {
+			t.Errorf("unexpected info: %+v", info)
+		}
+	})
+
+	t.Run("failure(ErrCryptoUnsupportedKeyType)", func(t *testing.T) {
+		t.Parallel()
+		if _, err := Crypto.KeyInfo(&dsa.PrivateKey{}); !errors.Is(err, ErrCryptoUnsupportedKeyType) {
+			t.Errorf("err != ErrCryptoUnsupportedKeyType: %v", err)
+		}
+	})
+}
@@ -0,0 +1,291 @@
+package nits
+
+import (
+	"context"
+	"crypto"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// ErrCryptoKeyPoolClosed key pool is closed.
+	ErrCryptoKeyPoolClosed = errors.New("key pool is closed")
+
+	// ErrCryptoInvalidKeyPoolOption invalid key pool option.
+	ErrCryptoInvalidKeyPoolOption = errors.New("invalid key pool option")
+)
+
+const (
+	cryptoKeyPoolDefaultWorkers       = 1
+	cryptoKeyPoolDefaultRetryInterval = time.Second
+)
+
+// KeyPool keeps private keys generated in the background ready for each CryptographicAlgorithm,
+// so that slow key generation such as CryptoRSA4096 does not happen on the request path.
+// The keys are generated by a bounded number of background workers, which refill the pool as soon as keys are taken from it.
+type KeyPool struct {
+	generateKey   func(algorithm CryptographicAlgorithm) (crypto.PrivateKey, error)
+	workers       int
+	retryInterval time.Duration
+
+	mu      sync.Mutex
+	entries map[CryptographicAlgorithm]*keyPoolEntry
+
+	refill chan struct{}
+	cancel context.CancelFunc
+	done   chan struct{}
+	wg     sync.WaitGroup
+}
+
+type keyPoolEntry struct {
+	size    int
+	keys    chan crypto.PrivateKey
+	errs    chan error // unbuffered, so that an error reaches only the Get calls waiting for it
+	pending int        // guarded by KeyPool.mu
+
+	hits      uint64
+	misses    uint64
+	generated uint64
+	failures  uint64
+}
+
+// KeyPoolStats is the statistics of the keys of a CryptographicAlgorithm in KeyPool.
+type KeyPoolStats struct {
+	// Size is the number of keys that the pool tries to keep ready.
+	Size int
+	// Depth is the number of keys ready now.
+	Depth int
+	// Pending is the number of keys being generated now.
+	Pending int
+	// Hits is the number of Get calls that took a key ready in the pool.
+	Hits uint64
+	// Misses is the number of Get calls that had to wait for a key to be generated.
+	Misses uint64
+	// Generated is the number of keys generated by the workers.
+	Generated uint64
+	// Failures is the number of key generations that failed.
+	Failures uint64
+}
+
+// KeyPoolOption is an option for Crypto.NewKeyPool.
+type KeyPoolOption func(*KeyPool)
+
+// WithKeyPoolWorkers sets the maximum number of keys generated concurrently. The default is 1.
+func (cryptoUtility) WithKeyPoolWorkers(workers int) KeyPoolOption {
+	return func(p *KeyPool) { p.workers = workers }
+}
+
+// WithKeyPoolRetryInterval sets the interval before a worker retries after key generation fails. The default is 1 second.
+func (cryptoUtility) WithKeyPoolRetryInterval(retryInterval time.Duration) KeyPoolOption {
+	return func(p *KeyPool) { p.retryInterval = retryInterval }
+}
+
+// WithKeyPoolGenerateKeyFunc replaces Crypto.GenerateKey used by the workers.
+func (cryptoUtility) WithKeyPoolGenerateKeyFunc(generateKey func(algorithm CryptographicAlgorithm) (crypto.PrivateKey, error)) KeyPoolOption {
+	return func(p *KeyPool) { p.generateKey = generateKey }
+}
+
+// NewKeyPool returns a *KeyPool that keeps the number of keys of sizes ready for each CryptographicAlgorithm, and starts its workers.
+// Each algorithm must pass CryptographicAlgorithm.Validate.
+// The workers stop when ctx is done or KeyPool.Close is called.
+func (cryptoUtility) NewKeyPool(ctx context.Context, sizes map[CryptographicAlgorithm]int, opts ...KeyPoolOption) (*KeyPool, error) {
+	p := &KeyPool{
+		generateKey:   Crypto.GenerateKey,
+		workers:       cryptoKeyPoolDefaultWorkers,
+		retryInterval: cryptoKeyPoolDefaultRetryInterval,
+		entries:       make(map[CryptographicAlgorithm]*keyPoolEntry, len(sizes)),
+		done:          make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	if p.workers < 1 {
+		return nil, fmt.Errorf("workers=%d: %w", p.workers, ErrCryptoInvalidKeyPoolOption)
+	}
+
+	for algorithm, size := range sizes {
+		if size < 1 {
+			return nil, fmt.Errorf("algorithm=%s size=%d: %w", algorithm, size, ErrCryptoInvalidKeyPoolOption)
+		}
+
+		// NOTE: The workers would retry an unsupported algorithm forever.
+		if err := algorithm.Validate(); err != nil {
+			return nil, fmt.Errorf("(CryptographicAlgorithm).Validate: %w", err)
+		}
+
+		p.entries[algorithm] = &keyPoolEntry{
+			size: size,
+			keys: make(chan crypto.PrivateKey, size),
+			errs: make(chan error),
+		}
+	}
+
+	p.refill = make(chan struct{}, 1)
+
+	ctx, p.cancel = context.WithCancel(ctx)
+
+	p.wg.Add(p.workers)
+
+	for i := 0; i < p.workers; i++ {
+		go p.work(ctx)
+	}
+
+	go func() {
+		<-ctx.Done()
+		p.wg.Wait()
+		close(p.done)
+	}()
+
+	p.notify()
+
+	return p, nil
+}
+
+// Close stops the workers and waits for them to finish. The keys ready in the pool are discarded.
+func (p *KeyPool) Close() error {
+	p.cancel()
+	<-p.done
+
+	return nil
+}
+
+// Get returns a private key of the algorithm. If no key is ready, it waits for a worker to generate one until ctx is done.
+// If the algorithm is not kept in the pool, the key is generated on the spot.
+func (p *KeyPool) Get(ctx context.Context, algorithm CryptographicAlgorithm) (crypto.PrivateKey, error) {
+	entry, ok := p.entries[algorithm]
+	if !ok {
+		return p.generateKey(algorithm)
+	}
+
+	select {
+	case <-p.done:
+		return nil, ErrCryptoKeyPoolClosed
+	default:
+	}
+
+	select {
+	case key := <-entry.keys:
+		atomic.AddUint64(&entry.hits, 1)
+		p.notify()
+
+		return key, nil
+	default:
+	}
+
+	atomic.AddUint64(&entry.misses, 1)
+
+	select {
+	case key := <-entry.keys:
+		p.notify()
+
+		return key, nil
+	case err := <-entry.errs:
+		return nil, fmt.Errorf("algorithm=%s: %w", algorithm, err)
+	case <-p.done:
+		return nil, ErrCryptoKeyPoolClosed
+	case <-ctx.Done():
+		return nil, fmt.Errorf("algorithm=%s: %w", algorithm, ctx.Err())
+	}
+}
+
+// Stats returns the statistics of each CryptographicAlgorithm in the pool.
+func (p *KeyPool) Stats() map[CryptographicAlgorithm]KeyPoolStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stats := make(map[CryptographicAlgorithm]KeyPoolStats, len(p.entries))
+	for algorithm, entry := range p.entries {
+		stats[algorithm] = KeyPoolStats{
+			Size:      entry.size,
+			Depth:     len(entry.keys),
+			Pending:   entry.pending,
+			Hits:      atomic.LoadUint64(&entry.hits),
+			Misses:    atomic.LoadUint64(&entry.misses),
+			Generated: atomic.LoadUint64(&entry.generated),
+			Failures:  atomic.LoadUint64(&entry.failures),
+		}
+	}
+
+	return stats
+}
+
+func (p *KeyPool) notify() {
+	select {
+	case p.refill <- struct{}{}:
+	default:
+	}
+}
+
+// claim reserves a slot of an algorithm whose pool is not full.
+func (p *KeyPool) claim() (CryptographicAlgorithm, *keyPoolEntry, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for algorithm, entry := range p.entries {
+		if len(entry.keys)+entry.pending < entry.size {
+			entry.pending++
+
+			return algorithm, entry, true
+		}
+	}
+
+	return "", nil, false
+}
+
+func (p *KeyPool) work(ctx context.Context) {
+	defer p.wg.Done()
+
+	for {
+		algorithm, entry, ok := p.claim()
+		if !ok {
+			select {
+			case <-ctx.Done():
+				return
+			case <-p.refill:
+				continue
+			}
+		}
+
+		// NOTE: Wake up another worker, because there may be more slots to fill.
+		p.notify()
+
+		key, err := p.generateKey(algorithm)
+
+		p.mu.Lock()
+		entry.pending--
+		if err == nil {
+			// NOTE: It never blocks, because the slot has been reserved by claim.
+			entry.keys <- key
+		}
+		p.mu.Unlock()
+
+		if err == nil {
+			atomic.AddUint64(&entry.generated, 1)
+
+			continue
+		}
+
+		atomic.AddUint64(&entry.failures, 1)
+
+		// NOTE: Deliver the error to every Get waiting now, and drop it if none is waiting,
+		//       so that a later Get does not receive a stale error after generation has recovered.
+		for delivered := true; delivered; {
+			select {
+			case entry.errs <- err:
+			default:
+				delivered = false
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(p.retryInterval):
+		}
+	}
+}
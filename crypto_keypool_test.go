@@ -0,0 +1,186 @@
+// nolint: testpackage
+package nits
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+func testWaitKeyPoolDepth(t *testing.T, pool *KeyPool, algorithm CryptographicAlgorithm, depth int) {
+	t.Helper()
+
+	for deadline := time.Now().Add(10 * time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		if pool.Stats()[algorithm].Depth == depth {
+			return
+		}
+	}
+
+	t.Fatalf("depth != %d: %+v", depth, pool.Stats()[algorithm])
+}
+
+func TestCryptoUtility_NewKeyPool(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success()", func(t *testing.T) {
+		t.Parallel()
+		pool, err := Crypto.NewKeyPool(context.Background(), map[CryptographicAlgorithm]int{CryptoEd25519: 3, CryptoECDSA256: 2}, Crypto.WithKeyPoolWorkers(2))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer pool.Close()
+
+		testWaitKeyPoolDepth(t, pool, CryptoEd25519, 3)
+		testWaitKeyPoolDepth(t, pool, CryptoECDSA256, 2)
+
+		privateKey, err := pool.Get(context.Background(), CryptoEd25519)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, ok := privateKey.(ed25519.PrivateKey); !ok {
+			t.Errorf("privateKey is not ed25519.PrivateKey: %T", privateKey)
+		}
+
+		testWaitKeyPoolDepth(t, pool, CryptoEd25519, 3)
+
+		stats := pool.Stats()[CryptoEd25519]
+		if stats.Size != 3 || stats.Hits != 1 || stats.Misses != 0 || stats.Generated != 4 {
+			t.Errorf("unexpected stats: %+v", stats)
+		}
+	})
+
+	t.Run("success(not pooled)", func(t *testing.T) {
+		t.Parallel()
+		pool, err := Crypto.NewKeyPool(context.Background(), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer pool.Close()
+
+		if _, err := pool.Get(context.Background(), CryptoEd25519); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("failure(ErrCryptoInvalidKeyPoolOption)", func(t *testing.T) {
+		t.Parallel()
+		if _, err := Crypto.NewKeyPool(context.Background(), nil, Crypto.WithKeyPoolWorkers(0)); !errors.Is(err, ErrCryptoInvalidKeyPoolOption) {
+			t.Errorf("err != ErrCryptoInvalidKeyPoolOption: %v", err)
+		}
+
+		if _, err := Crypto.NewKeyPool(context.Background(), map[CryptographicAlgorithm]int{CryptoEd25519: 0}); !errors.Is(err, ErrCryptoInvalidKeyPoolOption) {
+			t.Errorf("err != ErrCryptoInvalidKeyPoolOption: %v", err)
+		}
+	})
+
+	t.Run("failure(ErrCryptoWeakCryptographicAlgorithm)", func(t *testing.T) {
+		t.Parallel()
+		if _, err := Crypto.NewKeyPool(context.Background(), map[CryptographicAlgorithm]int{"rsa512": 1}); !errors.Is(err, ErrCryptoWeakCryptographicAlgorithm) {
+			t.Errorf("err != ErrCryptoWeakCryptographicAlgorithm: %v", err)
+		}
+
+		if _, err := Crypto.NewKeyPool(context.Background(), map[CryptographicAlgorithm]int{"dsa": 1}); !errors.Is(err, ErrCryptoNoSuchCryptographicAlgorithm) {
+			t.Errorf("err != ErrCryptoNoSuchCryptographicAlgorithm: %v", err)
+		}
+	})
+}
+
+func TestKeyPool_Get(t *testing.T) {
+	t.Parallel()
+
+	t.Run("failure(context.DeadlineExceeded)", func(t *testing.T) {
+		t.Parallel()
+		block := make(chan struct{})
+		defer close(block)
+
+		pool, err := Crypto.NewKeyPool(context.Background(), map[CryptographicAlgorithm]int{CryptoRSA4096: 1}, Crypto.WithKeyPoolGenerateKeyFunc(func(algorithm CryptographicAlgorithm) (crypto.PrivateKey, error) {
+			<-block
+
+			return Crypto.GenerateKey(CryptoEd25519)
+		}))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		if _, err := pool.Get(ctx, CryptoRSA4096); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("err != context.DeadlineExceeded: %v", err)
+		}
+
+		if stats := pool.Stats()[CryptoRSA4096]; stats.Misses != 1 || stats.Pending != 1 {
+			t.Errorf("unexpected stats: %+v", stats)
+		}
+	})
+
+	t.Run("failure(generate)", func(t *testing.T) {
+		t.Parallel()
+		pool, err := Crypto.NewKeyPool(context.Background(), map[CryptographicAlgorithm]int{CryptoEd25519: 1}, Crypto.WithKeyPoolRetryInterval(time.Millisecond), Crypto.WithKeyPoolGenerateKeyFunc(func(algorithm CryptographicAlgorithm) (crypto.PrivateKey, error) {
+			return nil, io.ErrUnexpectedEOF
+		}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer pool.Close()
+
+		if _, err := pool.Get(context.Background(), CryptoEd25519); !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Errorf("err != io.ErrUnexpectedEOF: %v", err)
+		}
+
+		if stats := pool.Stats()[CryptoEd25519]; stats.Failures < 1 {
+			t.Errorf("unexpected stats: %+v", stats)
+		}
+	})
+
+	t.Run("success(recovered)", func(t *testing.T) {
+		t.Parallel()
+		failed := make(chan struct{})
+		release := make(chan struct{})
+		calls := 0
+
+		pool, err := Crypto.NewKeyPool(context.Background(), map[CryptographicAlgorithm]int{CryptoEd25519: 1}, Crypto.WithKeyPoolRetryInterval(time.Millisecond), Crypto.WithKeyPoolGenerateKeyFunc(func(algorithm CryptographicAlgorithm) (crypto.PrivateKey, error) {
+			if calls++; calls == 1 {
+				defer close(failed)
+
+				return nil, io.ErrUnexpectedEOF
+			}
+
+			<-release
+
+			return Crypto.GenerateKey(algorithm)
+		}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer pool.Close()
+
+		<-failed
+
+		// NOTE: The error of the failure before Get must not be returned after generation has recovered.
+		time.AfterFunc(10*time.Millisecond, func() { close(release) })
+
+		if _, err := pool.Get(context.Background(), CryptoEd25519); err != nil {
+			t.Errorf("err != nil: %v", err)
+		}
+	})
+
+	t.Run("failure(ErrCryptoKeyPoolClosed)", func(t *testing.T) {
+		t.Parallel()
+		pool, err := Crypto.NewKeyPool(context.Background(), map[CryptographicAlgorithm]int{CryptoEd25519: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_ = pool.Close()
+
+		if _, err := pool.Get(context.Background(), CryptoEd25519); !errors.Is(err, ErrCryptoKeyPoolClosed) {
+			t.Errorf("err != ErrCryptoKeyPoolClosed: %v", err)
+		}
+	})
+}
@@ -0,0 +1,420 @@
+package nits
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrCryptoNoActiveKey no active key.
+	ErrCryptoNoActiveKey = errors.New("no active key")
+
+	// ErrCryptoKeyAlreadyExists key already exists.
+	ErrCryptoKeyAlreadyExists = errors.New("key already exists")
+
+	// ErrCryptoKeyHasExpired key has expired.
+	ErrCryptoKeyHasExpired = errors.New("key has expired")
+)
+
+// KeyringKeyState is an alias of string.
+type KeyringKeyState = string
+
+const (
+	// KeyringKeyStateNext the key is scheduled to become active at NotBefore.
+	KeyringKeyStateNext KeyringKeyState = "next"
+	// KeyringKeyStateActive the key is used for signing.
+	KeyringKeyStateActive KeyringKeyState = "active"
+	// KeyringKeyStateRetired the key is no longer used for signing, but is still used for verification until NotAfter.
+	KeyringKeyStateRetired KeyringKeyState = "retired"
+	// KeyringKeyStateExpired the key is used for neither signing nor verification.
+	KeyringKeyStateExpired KeyringKeyState = "expired"
+
+	keyringPEMHeaderKeyID     = "Key-ID"
+	keyringPEMHeaderNotBefore = "Not-Before"
+	keyringPEMHeaderNotAfter  = "Not-After"
+	keyringPEMHeaderRetired   = "Retired"
+)
+
+// Keyring holds signing keys identified by ID with validity windows.
+// The state of each key is determined by the clock, so the next key is promoted to active at its NotBefore without any operation:
+// among the keys that are not retired and whose validity window contains now, the one with the latest NotBefore is active.
+type Keyring struct {
+	mu   sync.RWMutex
+	now  func() time.Time
+	keys map[string]*KeyringKey
+}
+
+// KeyringKey is a key in Keyring.
+type KeyringKey struct {
+	ID         string
+	PrivateKey crypto.PrivateKey
+	// NotBefore is the time when the key becomes active.
+	NotBefore time.Time
+	// NotAfter is the time when the key expires. If it is zero, the key never expires.
+	NotAfter time.Time
+	// Retired reports whether the key has been retired by Keyring.Retire before it expires.
+	Retired bool
+}
+
+// KeyringKeyStatus is the status of a key in Keyring.
+type KeyringKeyStatus struct {
+	ID        string          `json:"id"`
+	State     KeyringKeyState `json:"state"`
+	NotBefore time.Time       `json:"notBefore"`
+	NotAfter  time.Time       `json:"notAfter,omitzero"`
+}
+
+// KeyringOption is an option for Crypto.NewKeyring.
+type KeyringOption func(*Keyring)
+
+// WithKeyringClock replaces time.Now used to determine the states of the keys.
+func (cryptoUtility) WithKeyringClock(now func() time.Time) KeyringOption {
+	return func(k *Keyring) { k.now = now }
+}
+
+// NewKeyring returns an empty *Keyring.
+func (cryptoUtility) NewKeyring(opts ...KeyringOption) *Keyring {
+	k := &Keyring{now: time.Now, keys: make(map[string]*KeyringKey)}
+	for _, opt := range opts {
+		opt(k)
+	}
+
+	return k
+}
+
+// Add adds the private key with the ID and the validity window.
+func (k *Keyring) Add(id string, privateKey crypto.PrivateKey, notBefore, notAfter time.Time) error {
+	if id == "" {
+		return fmt.Errorf("id is empty: %w", ErrCryptoInvalidKey)
+	}
+
+	if _, ok := privateKey.(crypto.Signer); !ok {
+		return fmt.Errorf("%T: %w", privateKey, ErrCryptoUnsupportedKeyType)
+	}
+
+	if !notAfter.IsZero() && !notBefore.Before(notAfter) {
+		return fmt.Errorf("id=%s notBefore=%s notAfter=%s: %w", id, notBefore, notAfter, ErrCryptoInvalidKey)
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if _, ok := k.keys[id]; ok {
+		return fmt.Errorf("id=%s: %w", id, ErrCryptoKeyAlreadyExists)
+	}
+
+	k.keys[id] = &KeyringKey{ID: id, PrivateKey: privateKey, NotBefore: notBefore, NotAfter: notAfter}
+
+	return nil
+}
+
+// Generate generates a key of the algorithm and adds it with the validity window. The ID is the RFC 7638 thumbprint of the key.
+func (k *Keyring) Generate(algorithm CryptographicAlgorithm, notBefore, notAfter time.Time) (id string, err error) {
+	privateKey, err := Crypto.GenerateKey(algorithm)
+	if err != nil {
+		return "", fmt.Errorf("Crypto.GenerateKey: %w", err)
+	}
+
+	jwk, err := JOSE.NewJWK(privateKey)
+	if err != nil {
+		return "", fmt.Errorf("JOSE.NewJWK: %w", err)
+	}
+
+	if err := k.Add(jwk.KeyID, privateKey, notBefore, notAfter); err != nil {
+		return "", err
+	}
+
+	return jwk.KeyID, nil
+}
+
+// Retire retires the key with the ID immediately. It is still used for verification until it expires.
+func (k *Keyring) Retire(id string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	key, ok := k.keys[id]
+	if !ok {
+		return fmt.Errorf("id=%s: %w", id, ErrCryptoKeyNotFound)
+	}
+
+	key.Retired = true
+
+	return nil
+}
+
+// Remove removes the key with the ID. Signatures made with the key can no longer be verified.
+func (k *Keyring) Remove(id string) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	delete(k.keys, id)
+}
+
+// Active returns a copy of the active key.
+func (k *Keyring) Active() (*KeyringKey, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	active, err := k.active(k.now())
+	if err != nil {
+		return nil, err
+	}
+
+	copied := *active
+
+	return &copied, nil
+}
+
+func (k *Keyring) active(now time.Time) (*KeyringKey, error) {
+	var active *KeyringKey
+
+	for _, key := range k.keys {
+		if key.Retired || now.Before(key.NotBefore) || k.expired(key, now) {
+			continue
+		}
+
+		if active == nil || key.NotBefore.After(active.NotBefore) || (key.NotBefore.Equal(active.NotBefore) && key.ID > active.ID) {
+			active = key
+		}
+	}
+
+	if active == nil {
+		return nil, ErrCryptoNoActiveKey
+	}
+
+	return active, nil
+}
+
+func (*Keyring) expired(key *KeyringKey, now time.Time) bool {
+	return !key.NotAfter.IsZero() && !now.Before(key.NotAfter)
+}
+
+func (k *Keyring) state(key *KeyringKey, active *KeyringKey, now time.Time) KeyringKeyState {
+	switch {
+	case k.expired(key, now):
+		return KeyringKeyStateExpired
+	case key == active:
+		return KeyringKeyStateActive
+	case !key.Retired && now.Before(key.NotBefore):
+		return KeyringKeyStateNext
+	}
+
+	return KeyringKeyStateRetired
+}
+
+// Keys returns the status of the keys sorted by NotBefore.
+func (k *Keyring) Keys() []KeyringKeyStatus {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	now := k.now()
+	active, _ := k.active(now)
+
+	statuses := make([]KeyringKeyStatus, 0, len(k.keys))
+	for _, key := range k.sorted() {
+		statuses = append(statuses, KeyringKeyStatus{
+			ID:        key.ID,
+			State:     k.state(key, active, now),
+			NotBefore: key.NotBefore,
+			NotAfter:  key.NotAfter,
+		})
+	}
+
+	return statuses
+}
+
+// Sign signs the message with the active key, and returns the ID of the key with the signature.
+func (k *Keyring) Sign(message []byte, opts ...CryptoSignOption) (id string, signature []byte, err error) {
+	key, err := k.Active()
+	if err != nil {
+		return "", nil, err
+	}
+
+	signature, err = Crypto.Sign(key.PrivateKey, message, opts...)
+	if err != nil {
+		return "", nil, fmt.Errorf("Crypto.Sign: %w", err)
+	}
+
+	return key.ID, signature, nil
+}
+
+// Verify verifies the signature with the key with the ID. Any key that has not expired is used, including retired keys.
+func (k *Keyring) Verify(id string, message, signature []byte, opts ...CryptoSignOption) error {
+	k.mu.RLock()
+	key, ok := k.keys[id]
+	expired := ok && k.expired(key, k.now())
+	k.mu.RUnlock()
+
+	if !ok {
+		return fmt.Errorf("id=%s: %w", id, ErrCryptoKeyNotFound)
+	}
+
+	if expired {
+		return fmt.Errorf("id=%s notAfter=%s: %w", id, key.NotAfter, ErrCryptoKeyHasExpired)
+	}
+
+	if err := Crypto.Verify(key.PrivateKey, message, signature, opts...); err != nil {
+		return fmt.Errorf("Crypto.Verify: %w", err)
+	}
+
+	return nil
+}
+
+// sorted returns the keys sorted by NotBefore, so that the output is stable.
+func (k *Keyring) sorted() []*KeyringKey {
+	keys := make([]*KeyringKey, 0, len(k.keys))
+	for _, key := range k.keys {
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].NotBefore.Equal(keys[j].NotBefore) {
+			return keys[i].ID < keys[j].ID
+		}
+
+		return keys[i].NotBefore.Before(keys[j].NotBefore)
+	})
+
+	return keys
+}
+
+// MarshalPEM returns the keys as concatenated PKCS#8 PEM blocks. The ID and the validity window are stored in the PEM headers.
+func (k *Keyring) MarshalPEM() ([]byte, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	buf := bytes.NewBuffer(nil)
+
+	for _, key := range k.sorted() {
+		der, err := x509.MarshalPKCS8PrivateKey(key.PrivateKey)
+		if err != nil {
+			return nil, fmt.Errorf("id=%s: x509.MarshalPKCS8PrivateKey: %w", key.ID, err)
+		}
+
+		headers := map[string]string{
+			keyringPEMHeaderKeyID:     key.ID,
+			keyringPEMHeaderNotBefore: key.NotBefore.UTC().Format(time.RFC3339Nano),
+		}
+		if !key.NotAfter.IsZero() {
+			headers[keyringPEMHeaderNotAfter] = key.NotAfter.UTC().Format(time.RFC3339Nano)
+		}
+
+		if key.Retired {
+			headers[keyringPEMHeaderRetired] = "true"
+		}
+
+		if err := pem.Encode(buf, &pem.Block{Type: "PRIVATE KEY", Headers: headers, Bytes: der}); err != nil {
+			return nil, fmt.Errorf("pem.Encode: %w", err)
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// ParseKeyringPEM returns *Keyring from the PEM data returned by Keyring.MarshalPEM.
+func (cryptoUtility) ParseKeyringPEM(pemData []byte, opts ...KeyringOption) (*Keyring, error) {
+	k := Crypto.NewKeyring(opts...)
+
+	for rest := pemData; ; {
+		var block *pem.Block
+		if block, rest = pem.Decode(rest); block == nil {
+			break
+		}
+
+		privateKey, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("x509.ParsePKCS8PrivateKey: %v: %w", err, ErrX509InvalidPEMFormat)
+		}
+
+		notBefore, err := time.Parse(time.RFC3339Nano, block.Headers[keyringPEMHeaderNotBefore])
+		if err != nil {
+			return nil, fmt.Errorf("%s: time.Parse: %v: %w", keyringPEMHeaderNotBefore, err, ErrX509InvalidPEMFormat)
+		}
+
+		var notAfter time.Time
+		if v, ok := block.Headers[keyringPEMHeaderNotAfter]; ok {
+			if notAfter, err = time.Parse(time.RFC3339Nano, v); err != nil {
+				return nil, fmt.Errorf("%s: time.Parse: %v: %w", keyringPEMHeaderNotAfter, err, ErrX509InvalidPEMFormat)
+			}
+		}
+
+		id := block.Headers[keyringPEMHeaderKeyID]
+		if err := k.Add(id, privateKey, notBefore, notAfter); err != nil {
+			return nil, err
+		}
+
+		k.keys[id].Retired = block.Headers[keyringPEMHeaderRetired] == "true"
+	}
+
+	return k, nil
+}
+
+type keyringJSON struct {
+	Keys []keyringKeyJSON `json:"keys"`
+}
+
+type keyringKeyJSON struct {
+	ID         string    `json:"id"`
+	NotBefore  time.Time `json:"notBefore"`
+	NotAfter   time.Time `json:"notAfter,omitzero"`
+	Retired    bool      `json:"retired,omitempty"`
+	PrivateKey string    `json:"privateKey"`
+}
+
+// MarshalJSON implements json.Marshaler. The private keys are stored as PKCS#8 PEM, so the output must be kept secret.
+func (k *Keyring) MarshalJSON() ([]byte, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	v := keyringJSON{Keys: make([]keyringKeyJSON, 0, len(k.keys))}
+
+	for _, key := range k.sorted() {
+		pemData, err := X509.MarshalPKCSXPrivateKeyPEM(key.PrivateKey)
+		if err != nil {
+			return nil, fmt.Errorf("id=%s: X509.MarshalPKCSXPrivateKeyPEM: %w", key.ID, err)
+		}
+
+		v.Keys = append(v.Keys, keyringKeyJSON{
+			ID:         key.ID,
+			NotBefore:  key.NotBefore,
+			NotAfter:   key.NotAfter,
+			Retired:    key.Retired,
+			PrivateKey: string(pemData),
+		})
+	}
+
+	return json.Marshal(v) // nolint: wrapcheck
+}
+
+// ParseKeyringJSON returns *Keyring from the JSON returned by Keyring.MarshalJSON.
+func (cryptoUtility) ParseKeyringJSON(data []byte, opts ...KeyringOption) (*Keyring, error) {
+	var v keyringJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal: %w", err)
+	}
+
+	k := Crypto.NewKeyring(opts...)
+
+	for _, key := range v.Keys {
+		privateKey, err := X509.ParsePKCSXPrivateKeyPEM([]byte(key.PrivateKey))
+		if err != nil {
+			return nil, fmt.Errorf("id=%s: X509.ParsePKCSXPrivateKeyPEM: %w", key.ID, err)
+		}
+
+		if err := k.Add(key.ID, privateKey, key.NotBefore, key.NotAfter); err != nil {
+			return nil, err
+		}
+
+		k.keys[key.ID].Retired = key.Retired
+	}
+
+	return k, nil
+}
@@ -0,0 +1,282 @@
+// nolint: testpackage
+package nits
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func testNewKeyring(t *testing.T, now *time.Time) (keyring *Keyring, retiredID, activeID, nextID string) {
+	t.Helper()
+
+	keyring = Crypto.NewKeyring(Crypto.WithKeyringClock(func() time.Time { return *now }))
+
+	for _, seed := range []struct {
+		id        string
+		notBefore time.Time
+	}{
+		{"retired", now.AddDate(0, 0, -90)},
+		{"active", *now},
+		{"next", now.AddDate(0, 0, 90)},
+	} {
+		privateKey := Crypto.MustGenerateKey(Crypto.GenerateKeyFromSeed(CryptoEd25519, []byte(seed.id)))
+		if err := keyring.Add(seed.id, privateKey, seed.notBefore, seed.notBefore.AddDate(0, 0, 180)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return keyring, "retired", "active", "next"
+}
+
+func testKeyringStates(keyring *Keyring) map[string]KeyringKeyState {
+	states := make(map[string]KeyringKeyState)
+	for _, status := range keyring.Keys() {
+		states[status.ID] = status.State
+	}
+
+	return states
+}
+
+func TestKeyring_Sign(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success(rotation)", func(t *testing.T) {
+		t.Parallel()
+		now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		keyring, retiredID, activeID, nextID := testNewKeyring(t, &now)
+
+		if states := testKeyringStates(keyring); states[retiredID] != KeyringKeyStateRetired || states[activeID] != KeyringKeyStateActive || states[nextID] != KeyringKeyStateNext {
+			t.Errorf("unexpected states: %v", states)
+		}
+
+		id, signature, err := keyring.Sign([]byte("message"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if id != activeID {
+			t.Errorf("id != activeID: %s", id)
+		}
+
+		// NOTE: the next key is promoted at its NotBefore.
+		now = now.AddDate(0, 0, 90)
+
+		if states := testKeyringStates(keyring); states[retiredID] != KeyringKeyStateExpired || states[activeID] != KeyringKeyStateRetired || states[nextID] != KeyringKeyStateActive {
+			t.Errorf("unexpected states: %v", states)
+		}
+
+		if err := keyring.Verify(id, []byte("message"), signature); err != nil {
+			t.Error(err)
+		}
+
+		if id, _, _ := keyring.Sign([]byte("message")); id != nextID {
+			t.Errorf("id != nextID: %s", id)
+		}
+
+		now = now.AddDate(0, 0, 90)
+
+		if err := keyring.Verify(activeID, []byte("message"), signature); !errors.Is(err, ErrCryptoKeyHasExpired) {
+			t.Errorf("err != ErrCryptoKeyHasExpired: %v", err)
+		}
+	})
+
+	t.Run("success(Retire)", func(t *testing.T) {
+		t.Parallel()
+		now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		keyring, retiredID, activeID, _ := testNewKeyring(t, &now)
+
+		if err := keyring.Retire(activeID); err != nil {
+			t.Fatal(err)
+		}
+
+		if id, _, _ := keyring.Sign([]byte("message")); id != retiredID {
+			t.Errorf("id != retiredID: %s", id)
+		}
+
+		if err := keyring.Retire("not found"); !errors.Is(err, ErrCryptoKeyNotFound) {
+			t.Errorf("err != ErrCryptoKeyNotFound: %v", err)
+		}
+	})
+
+	t.Run("failure(ErrCryptoNoActiveKey)", func(t *testing.T) {
+		t.Parallel()
+		if _, _, err := Crypto.NewKeyring().Sign([]byte("message")); !errors.Is(err, ErrCryptoNoActiveKey) {
+			t.Errorf("err != ErrCryptoNoActiveKey: %v", err)
+		}
+	})
+}
+
+func TestKeyring_Verify(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	keyring, retiredID, activeID, _ := testNewKeyring(t, &now)
+
+	_, signature, err := keyring.Sign([]byte("message"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("failure(ErrCryptoInvalidSignature)", func(t *testing.T) {
+		t.Parallel()
+		if err := keyring.Verify(retiredID, []byte("message"), signature); !errors.Is(err, ErrCryptoInvalidSignature) {
+			t.Errorf("err != ErrCryptoInvalidSignature: %v", err)
+		}
+	})
+
+	t.Run("failure(ErrCryptoKeyNotFound)", func(t *testing.T) {
+		t.Parallel()
+		keyring.Remove(activeID)
+
+		if err := keyring.Verify(activeID, []byte("message"), signature); !errors.Is(err, ErrCryptoKeyNotFound) {
+			t.Errorf("err != ErrCryptoKeyNotFound: %v", err)
+		}
+	})
+}
+
+func TestKeyring_Add(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	keyring, _, activeID, _ := testNewKeyring(t, &now)
+	privateKey := Crypto.MustGenerateKey(Crypto.GenerateKey(CryptoEd25519))
+
+	t.Run("failure(ErrCryptoKeyAlreadyExists)", func(t *testing.T) {
+		t.Parallel()
+		if err := keyring.Add(activeID, privateKey, now, time.Time{}); !errors.Is(err, ErrCryptoKeyAlreadyExists) {
+			t.Errorf("err != ErrCryptoKeyAlreadyExists: %v", err)
+		}
+	})
+
+	t.Run("failure(ErrCryptoInvalidKey)", func(t *testing.T) {
+		t.Parallel()
+		if err := keyring.Add("", privateKey, now, time.Time{}); !errors.Is(err, ErrCryptoInvalidKey) {
+			t.Errorf("err != ErrCryptoInvalidKey: %v", err)
+		}
+
+		if err := keyring.Add("id", privateKey, now, now); !errors.Is(err, ErrCryptoInvalidKey) {
+			t.Errorf("err != ErrCryptoInvalidKey: %v", err)
+		}
+	})
+
+	t.Run("failure(ErrCryptoUnsupportedKeyType)", func(t *testing.T) {
+		t.Parallel()
+		if err := keyring.Add("id", Crypto.MustGenerateKey(Crypto.GenerateKey(CryptoX25519)), now, time.Time{}); !errors.Is(err, ErrCryptoUnsupportedKeyType) {
+			t.Errorf("err != ErrCryptoUnsupportedKeyType: %v", err)
+		}
+	})
+
+	t.Run("success(Generate)", func(t *testing.T) {
+		t.Parallel()
+		id, err := keyring.Generate(CryptoECDSA256, now.AddDate(0, 0, 1), time.Time{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if states := testKeyringStates(keyring); states[id] != KeyringKeyStateNext {
+			t.Errorf("unexpected states: %v", states)
+		}
+	})
+}
+
+func TestKeyring_MarshalPEM(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	keyring, retiredID, _, _ := testNewKeyring(t, &now)
+	clock := Crypto.WithKeyringClock(func() time.Time { return now })
+
+	if err := keyring.Retire(retiredID); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("success(PEM)", func(t *testing.T) {
+		t.Parallel()
+		pemData, err := keyring.MarshalPEM()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		parsed, err := Crypto.ParseKeyringPEM(pemData, clock)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if actual, expect := testKeyringStates(parsed), testKeyringStates(keyring); len(actual) != 3 || actual[retiredID] != expect[retiredID] {
+			t.Errorf("actual != expect: %v != %v", actual, expect)
+		}
+
+		if again, _ := parsed.MarshalPEM(); string(again) != string(pemData) {
+			t.Errorf("unstable PEM: %s", again)
+		}
+	})
+
+	t.Run("success(JSON)", func(t *testing.T) {
+		t.Parallel()
+		data, err := keyring.MarshalJSON()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		parsed, err := Crypto.ParseKeyringJSON(data, clock)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if again, _ := parsed.MarshalJSON(); string(again) != string(data) {
+			t.Errorf("unstable JSON: %s", again)
+		}
+	})
+
+	t.Run("success(PEM<->JSON)", func(t *testing.T) {
+		t.Parallel()
+		keyring := Crypto.NewKeyring(clock)
+		notBefore := now.Add(-1500 * time.Millisecond)
+
+		if err := keyring.Add("subsecond", Crypto.MustGenerateKey(Crypto.GenerateKeyFromSeed(CryptoEd25519, []byte("subsecond"))), notBefore, notBefore.Add(time.Hour+time.Nanosecond)); err != nil {
+			t.Fatal(err)
+		}
+
+		pemData, err := keyring.MarshalPEM()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		fromPEM, err := Crypto.ParseKeyringPEM(pemData, clock)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		jsonData, err := fromPEM.MarshalJSON()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		fromJSON, err := Crypto.ParseKeyringJSON(jsonData, clock)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, parsed := range []*Keyring{fromPEM, fromJSON} {
+			if actual, expect := parsed.Keys()[0], keyring.Keys()[0]; !actual.NotBefore.Equal(expect.NotBefore) || !actual.NotAfter.Equal(expect.NotAfter) {
+				t.Errorf("actual != expect: %+v != %+v", actual, expect)
+			}
+		}
+
+		if again, _ := fromJSON.MarshalPEM(); string(again) != string(pemData) {
+			t.Errorf("unstable PEM: %s", again)
+		}
+	})
+
+	t.Run("failure()", func(t *testing.T) {
+		t.Parallel()
+		if _, err := Crypto.ParseKeyringPEM([]byte(testPKCS1KeyPEMString)); !errors.Is(err, ErrX509InvalidPEMFormat) {
+			t.Errorf("err != ErrX509InvalidPEMFormat: %v", err)
+		}
+
+		if _, err := Crypto.ParseKeyringJSON([]byte("{")); err == nil {
+			t.Error("err == nil")
+		}
+	})
+}
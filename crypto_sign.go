@@ -0,0 +1,226 @@
+package nits
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	_ "crypto/sha256" // for crypto.SHA256
+	_ "crypto/sha512" // for crypto.SHA384, crypto.SHA512
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+var (
+	// ErrCryptoUnsupportedKeyType unsupported key type.
+	ErrCryptoUnsupportedKeyType = errors.New("unsupported key type")
+
+	// ErrCryptoInvalidSignature invalid signature.
+	ErrCryptoInvalidSignature = errors.New("invalid signature")
+)
+
+// CryptoSignOption is an option for Crypto.Sign and Crypto.Verify.
+type CryptoSignOption func(*cryptoSignOptions)
+
+type cryptoSignOptions struct {
+	hash     crypto.Hash
+	pss      bool
+	ecdsaRaw bool
+}
+
+// WithHash overrides the hash function chosen from the key. It is ignored for Ed25519.
+func (cryptoUtility) WithHash(hash crypto.Hash) CryptoSignOption {
+	return func(o *cryptoSignOptions) { o.hash = hash }
+}
+
+// WithPSS makes RSA keys use RSASSA-PSS instead of RSASSA-PKCS1-v1_5.
+func (cryptoUtility) WithPSS() CryptoSignOption {
+	return func(o *cryptoSignOptions) { o.pss = true }
+}
+
+// WithRawECDSASignature makes ECDSA keys use the fixed-length r||s encoding instead of ASN.1 DER.
+func (cryptoUtility) WithRawECDSASignature() CryptoSignOption {
+	return func(o *cryptoSignOptions) { o.ecdsaRaw = true }
+}
+
+// Sign signs the message with the private key.
+// The signature scheme is chosen from the key type, and the hash from the CryptographicAlgorithm of the key:
+// SHA-256 for CryptoRSA2048, CryptoRSA4096 and CryptoECDSA256, SHA-384 for CryptoRSA8192 and CryptoECDSA384.
+func (cryptoUtility) Sign(privateKey crypto.PrivateKey, message []byte, opts ...CryptoSignOption) (signature []byte, err error) {
+	switch priv := privateKey.(type) {
+	case ed25519.PrivateKey:
+		if len(priv) != ed25519.PrivateKeySize {
+			return nil, fmt.Errorf("ed25519 private key length=%d: %w", len(priv), ErrCryptoInvalidKey)
+		}
+
+		return ed25519.Sign(priv, message), nil
+	case *ed25519.PrivateKey:
+		return Crypto.Sign(*priv, message, opts...)
+	case *rsa.PrivateKey:
+		o := Crypto.newSignOptions(&priv.PublicKey, opts)
+		digest, err := Crypto.digest(o.hash, message)
+		if err != nil {
+			return nil, err
+		}
+		if o.pss {
+			signature, err = rsa.SignPSS(rand.Reader, priv, o.hash, digest, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+			if err != nil {
+				return nil, fmt.Errorf("rsa.SignPSS: %w", err)
+			}
+
+			return signature, nil
+		}
+
+		signature, err = rsa.SignPKCS1v15(rand.Reader, priv, o.hash, digest)
+		if err != nil {
+			return nil, fmt.Errorf("rsa.SignPKCS1v15: %w", err)
+		}
+
+		return signature, nil
+	case *ecdsa.PrivateKey:
+		o := Crypto.newSignOptions(&priv.PublicKey, opts)
+		digest, err := Crypto.digest(o.hash, message)
+		if err != nil {
+			return nil, err
+		}
+		if o.ecdsaRaw {
+			r, s, err := ecdsa.Sign(rand.Reader, priv, digest)
+			if err != nil {
+				return nil, fmt.Errorf("ecdsa.Sign: %w", err)
+			}
+
+			size := (priv.Curve.Params().BitSize + 7) / 8 // nolint: gomnd
+			signature = make([]byte, 2*size)              // nolint: gomnd
+			r.FillBytes(signature[:size])
+			s.FillBytes(signature[size:])
+
+			return signature, nil
+		}
+
+		signature, err = ecdsa.SignASN1(rand.Reader, priv, digest)
+		if err != nil {
+			return nil, fmt.Errorf("ecdsa.SignASN1: %w", err)
+		}
+
+		return signature, nil
+	}
+
+	return nil, fmt.Errorf("%T: %w", privateKey, ErrCryptoUnsupportedKeyType)
+}
+
+// Verify verifies the signature of the message with the public key.
+// The options must be the same as those passed to Crypto.Sign. A private key is also accepted in place of the public key.
+func (cryptoUtility) Verify(publicKey crypto.PublicKey, message, signature []byte, opts ...CryptoSignOption) error {
+	if priv, ok := publicKey.(*ed25519.PrivateKey); ok {
+		publicKey = *priv
+	}
+
+	// (ed25519.PrivateKey).Public panics on a key of a wrong length.
+	if priv, ok := publicKey.(ed25519.PrivateKey); ok && len(priv) != ed25519.PrivateKeySize {
+		return fmt.Errorf("ed25519 private key length=%d: %w", len(priv), ErrCryptoInvalidKey)
+	}
+
+	if priv, ok := publicKey.(interface{ Public() crypto.PublicKey }); ok {
+		publicKey = priv.Public()
+	}
+
+	switch pub := publicKey.(type) {
+	case ed25519.PublicKey:
+		if len(pub) != ed25519.PublicKeySize {
+			return fmt.Errorf("ed25519 public key length=%d: %w", len(pub), ErrCryptoInvalidKey)
+		}
+
+		if !ed25519.Verify(pub, message, signature) {
+			return ErrCryptoInvalidSignature
+		}
+
+		return nil
+	case *rsa.PublicKey:
+		o := Crypto.newSignOptions(pub, opts)
+		digest, err := Crypto.digest(o.hash, message)
+		if err != nil {
+			return err
+		}
+		if o.pss {
+			if err := rsa.VerifyPSS(pub, o.hash, digest, signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthAuto}); err != nil {
+				return fmt.Errorf("rsa.VerifyPSS: %v: %w", err, ErrCryptoInvalidSignature)
+			}
+
+			return nil
+		}
+
+		if err := rsa.VerifyPKCS1v15(pub, o.hash, digest, signature); err != nil {
+			return fmt.Errorf("rsa.VerifyPKCS1v15: %v: %w", err, ErrCryptoInvalidSignature)
+		}
+
+		return nil
+	case *ecdsa.PublicKey:
+		o := Crypto.newSignOptions(pub, opts)
+		digest, err := Crypto.digest(o.hash, message)
+		if err != nil {
+			return err
+		}
+		if o.ecdsaRaw {
+			size := (pub.Curve.Params().BitSize + 7) / 8 // nolint: gomnd
+			if len(signature) != 2*size {
+				return fmt.Errorf("signature length=%d: %w", len(signature), ErrCryptoInvalidSignature)
+			}
+
+			r, s := new(big.Int).SetBytes(signature[:size]), new(big.Int).SetBytes(signature[size:])
+			if !ecdsa.Verify(pub, digest, r, s) {
+				return ErrCryptoInvalidSignature
+			}
+
+			return nil
+		}
+
+		if !ecdsa.VerifyASN1(pub, digest, signature) {
+			return ErrCryptoInvalidSignature
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf("%T: %w", publicKey, ErrCryptoUnsupportedKeyType)
+}
+
+func (cryptoUtility) newSignOptions(publicKey crypto.PublicKey, opts []CryptoSignOption) *cryptoSignOptions {
+	o := &cryptoSignOptions{hash: Crypto.hashFor(publicKey)}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// hashFor returns the hash that matches the security strength of the CryptographicAlgorithm of the key.
+func (cryptoUtility) hashFor(publicKey crypto.PublicKey) crypto.Hash {
+	switch pub := publicKey.(type) {
+	case *ecdsa.PublicKey:
+		switch bitSize := pub.Curve.Params().BitSize; {
+		case bitSize > 384: // nolint: gomnd
+			return crypto.SHA512
+		case bitSize > 256: // nolint: gomnd
+			return crypto.SHA384
+		}
+	case *rsa.PublicKey:
+		if pub.N.BitLen() > 4096 { // nolint: gomnd
+			return crypto.SHA384
+		}
+	}
+
+	return crypto.SHA256
+}
+
+func (cryptoUtility) digest(hash crypto.Hash, message []byte) ([]byte, error) {
+	if !hash.Available() {
+		return nil, fmt.Errorf("hash=%v: %w", hash, ErrCryptoNoSuchCryptographicAlgorithm)
+	}
+
+	h := hash.New()
+	_, _ = h.Write(message)
+
+	return h.Sum(nil), nil
+}
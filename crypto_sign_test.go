@@ -0,0 +1,111 @@
+// nolint: testpackage
+package nits
+
+import (
+	"crypto"
+	"crypto/dsa" // nolint: staticcheck
+	"crypto/ed25519"
+	"errors"
+	"testing"
+)
+
+func Test_cryptoUtility_Sign_Verify(t *testing.T) {
+	t.Parallel()
+
+	message := []byte("message")
+	tests := []struct {
+		name      string
+		algorithm CryptographicAlgorithm
+		opts      []CryptoSignOption
+	}{
+		{"success(CryptoRSA2048)", CryptoRSA2048, nil},
+		{"success(CryptoRSA2048,PSS)", CryptoRSA2048, []CryptoSignOption{Crypto.WithPSS()}},
+		{"success(CryptoRSA2048,SHA512)", CryptoRSA2048, []CryptoSignOption{Crypto.WithHash(crypto.SHA512)}},
+		{"success(CryptoECDSA256)", CryptoECDSA256, nil},
+		{"success(CryptoECDSA256,Raw)", CryptoECDSA256, []CryptoSignOption{Crypto.WithRawECDSASignature()}},
+		{"success(CryptoECDSA384)", CryptoECDSA384, nil},
+		{"success(CryptoECDSA384,Raw)", CryptoECDSA384, []CryptoSignOption{Crypto.WithRawECDSASignature()}},
+		{"success(CryptoEd25519)", CryptoEd25519, nil},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			privateKey, err := Crypto.GenerateKey(tt.algorithm)
+			if err != nil {
+				t.Fatalf("Crypto.GenerateKey: %v", err)
+			}
+			signature, err := Crypto.Sign(privateKey, message, tt.opts...)
+			if err != nil {
+				t.Fatalf("Crypto.Sign: %v", err)
+			}
+			publicKey := privateKey.(interface{ Public() crypto.PublicKey }).Public() // nolint: forcetypeassert
+			if err := Crypto.Verify(publicKey, message, signature, tt.opts...); err != nil {
+				t.Errorf("Crypto.Verify: %v", err)
+			}
+			if err := Crypto.Verify(privateKey, message, signature, tt.opts...); err != nil {
+				t.Errorf("Crypto.Verify(privateKey): %v", err)
+			}
+			if err := Crypto.Verify(publicKey, []byte("tampered"), signature, tt.opts...); !errors.Is(err, ErrCryptoInvalidSignature) {
+				t.Errorf("Crypto.Verify(tampered): %v", err)
+			}
+		})
+	}
+}
+
+func Test_cryptoUtility_Sign(t *testing.T) {
+	t.Parallel()
+
+	t.Run("failure(ErrCryptoUnsupportedKeyType)", func(t *testing.T) {
+		t.Parallel()
+		if _, err := Crypto.Sign(&dsa.PrivateKey{}, []byte("message")); !errors.Is(err, ErrCryptoUnsupportedKeyType) {
+			t.Errorf("err != ErrCryptoUnsupportedKeyType: %v", err)
+		}
+	})
+
+	t.Run("failure(ErrCryptoNoSuchCryptographicAlgorithm)", func(t *testing.T) {
+		t.Parallel()
+		privateKey := Crypto.MustGenerateKey(Crypto.GenerateKey(CryptoECDSA256))
+		if _, err := Crypto.Sign(privateKey, []byte("message"), Crypto.WithHash(crypto.MD4)); !errors.Is(err, ErrCryptoNoSuchCryptographicAlgorithm) {
+			t.Errorf("err != ErrCryptoNoSuchCryptographicAlgorithm: %v", err)
+		}
+	})
+
+	t.Run("failure(ErrCryptoInvalidKey)", func(t *testing.T) {
+		t.Parallel()
+		privateKey := ed25519.PrivateKey("short")
+		for _, key := range []crypto.PrivateKey{privateKey, &privateKey} {
+			if _, err := Crypto.Sign(key, []byte("message")); !errors.Is(err, ErrCryptoInvalidKey) {
+				t.Errorf("%T: err != ErrCryptoInvalidKey: %v", key, err)
+			}
+		}
+	})
+}
+
+func Test_cryptoUtility_Verify(t *testing.T) {
+	t.Parallel()
+
+	t.Run("failure(ErrCryptoUnsupportedKeyType)", func(t *testing.T) {
+		t.Parallel()
+		if err := Crypto.Verify(&dsa.PublicKey{}, []byte("message"), nil); !errors.Is(err, ErrCryptoUnsupportedKeyType) {
+			t.Errorf("err != ErrCryptoUnsupportedKeyType: %v", err)
+		}
+	})
+
+	t.Run("failure(Raw,InvalidLength)", func(t *testing.T) {
+		t.Parallel()
+		privateKey := Crypto.MustGenerateKey(Crypto.GenerateKey(CryptoECDSA256))
+		if err := Crypto.Verify(privateKey, []byte("message"), []byte("short"), Crypto.WithRawECDSASignature()); !errors.Is(err, ErrCryptoInvalidSignature) {
+			t.Errorf("err != ErrCryptoInvalidSignature: %v", err)
+		}
+	})
+
+	t.Run("failure(ErrCryptoInvalidKey)", func(t *testing.T) {
+		t.Parallel()
+		for _, key := range []crypto.PublicKey{ed25519.PublicKey("short"), ed25519.PrivateKey("short"), ed25519.PrivateKey{}} {
+			if err := Crypto.Verify(key, []byte("message"), make([]byte, ed25519.SignatureSize)); !errors.Is(err, ErrCryptoInvalidKey) {
+				t.Errorf("%T: err != ErrCryptoInvalidKey: %v", key, err)
+			}
+		}
+	})
+}
@@ -0,0 +1,423 @@
+package nits
+
+import (
+	"bufio"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+var (
+	// ErrCryptoKeyNotFound key not found.
+	ErrCryptoKeyNotFound = errors.New("key not found")
+
+	// ErrCryptoInvalidKey invalid key.
+	ErrCryptoInvalidKey = errors.New("invalid key")
+
+	// ErrCryptoNoPrimaryKey no primary key.
+	ErrCryptoNoPrimaryKey = errors.New("no primary key")
+)
+
+const (
+	// CryptoSymmetricKeySize is the size of the keys in SymmetricKeyRing.
+	CryptoSymmetricKeySize = 32
+	// CryptoStreamChunkSize is the size of the plaintext chunks of the streaming encryption.
+	CryptoStreamChunkSize = 64 * 1024
+
+	cryptoSealVersion      = 1
+	cryptoStreamVersion    = 2
+	cryptoStreamSaltSize   = 16
+	cryptoStreamInfo       = "nits stream chunk key"
+	cryptoStreamLastChunk  = 1
+	cryptoMaxKeyIDLength   = 255
+	cryptoStreamNonceIndex = 11
+)
+
+// SymmetricKeyRing holds AES-256-GCM keys identified by ID.
+// Seal and NewEncryptWriter use the primary key, and every key in the ring can decrypt,
+// so retired keys remain usable for data that was encrypted before the rotation.
+type SymmetricKeyRing struct {
+	mu      sync.RWMutex
+	primary string
+	keys    map[string][]byte
+}
+
+// NewSymmetricKeyRing returns an empty *SymmetricKeyRing.
+func (cryptoUtility) NewSymmetricKeyRing() *SymmetricKeyRing {
+	return &SymmetricKeyRing{keys: make(map[string][]byte)}
+}
+
+// GenerateSymmetricKey returns a random 256-bit key.
+func (cryptoUtility) GenerateSymmetricKey() ([]byte, error) {
+	key := make([]byte, CryptoSymmetricKeySize)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, fmt.Errorf("rand.Reader: %w", err)
+	}
+
+	return key, nil
+}
+
+// Add adds the 256-bit key with the ID. The first key added becomes the primary key.
+func (r *SymmetricKeyRing) Add(id string, key []byte) error {
+	if id == "" || len(id) > cryptoMaxKeyIDLength {
+		return fmt.Errorf("id=%q: length must be 1-%d: %w", id, cryptoMaxKeyIDLength, ErrCryptoInvalidKey)
+	}
+
+	if len(key) != CryptoSymmetricKeySize {
+		return fmt.Errorf("id=%s: size=%d: %w", id, len(key), ErrCryptoInvalidKey)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.keys[id] = append([]byte{}, key...)
+	if r.primary == "" {
+		r.primary = id
+	}
+
+	return nil
+}
+
+// Rotate generates a new key with the ID and makes it the primary key. The former primary key is retired, and is used only for decryption.
+func (r *SymmetricKeyRing) Rotate(id string) error {
+	key, err := Crypto.GenerateSymmetricKey()
+	if err != nil {
+		return fmt.Errorf("Crypto.GenerateSymmetricKey: %w", err)
+	}
+
+	if err := r.Add(id, key); err != nil {
+		return err
+	}
+
+	return r.SetPrimary(id)
+}
+
+// SetPrimary makes the key with the ID the primary key.
+func (r *SymmetricKeyRing) SetPrimary(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.keys[id]; !ok {
+		return fmt.Errorf("id=%s: %w", id, ErrCryptoKeyNotFound)
+	}
+
+	r.primary = id
+
+	return nil
+}
+
+// Primary returns the ID of the primary key.
+func (r *SymmetricKeyRing) Primary() string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.primary
+}
+
+// Remove removes the key with the ID. Data encrypted with the key can no longer be decrypted.
+func (r *SymmetricKeyRing) Remove(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.keys, id)
+	if r.primary == id {
+		r.primary = ""
+	}
+}
+
+func (r *SymmetricKeyRing) primaryKey() (id string, key []byte, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.primary == "" {
+		return "", nil, ErrCryptoNoPrimaryKey
+	}
+
+	return r.primary, r.keys[r.primary], nil
+}
+
+func (r *SymmetricKeyRing) key(id string) ([]byte, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	key, ok := r.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("id=%s: %w", id, ErrCryptoKeyNotFound)
+	}
+
+	return key, nil
+}
+
+// Seal encrypts the plaintext with the primary key, and returns a versioned envelope:
+//
+//	version (1 byte) || len(key ID) (1 byte) || key ID || nonce (12 bytes) || AES-256-GCM ciphertext
+//
+// The additionalData is authenticated but not encrypted, and must be passed to Open as well.
+func (r *SymmetricKeyRing) Seal(plaintext, additionalData []byte) (envelope []byte, err error) {
+	id, key, err := r.primaryKey()
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := Crypto.newAESGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	header := append([]byte{cryptoSealVersion, byte(len(id))}, id...)
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("rand.Reader: %w", err)
+	}
+
+	envelope = append(append([]byte{}, header...), nonce...)
+
+	return aead.Seal(envelope, nonce, plaintext, append(header, additionalData...)), nil
+}
+
+// Open decrypts the envelope returned by Seal with the key recorded in it.
+func (r *SymmetricKeyRing) Open(envelope, additionalData []byte) (plaintext []byte, err error) {
+	id, body, err := Crypto.unmarshalKeyIDHeader(envelope, cryptoSealVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := r.key(id)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := Crypto.newAESGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(body) < aead.NonceSize() {
+		return nil, fmt.Errorf("nonce: too short: %w", ErrCryptoInvalidCiphertext)
+	}
+
+	header := envelope[:len(envelope)-len(body)]
+
+	plaintext, err = aead.Open(nil, body[:aead.NonceSize()], body[aead.NonceSize():], append(append([]byte{}, header...), additionalData...))
+	if err != nil {
+		return nil, fmt.Errorf("(cipher.AEAD).Open: %v: %w", err, ErrCryptoInvalidCiphertext)
+	}
+
+	return plaintext, nil
+}
+
+func (cryptoUtility) unmarshalKeyIDHeader(data []byte, version byte) (id string, rest []byte, err error) {
+	if len(data) < 2 || data[0] != version { // nolint: gomnd
+		return "", nil, fmt.Errorf("version: %w", ErrCryptoInvalidCiphertext)
+	}
+
+	idLength := int(data[1])
+	if len(data[2:]) < idLength {
+		return "", nil, fmt.Errorf("key ID: too short: %w", ErrCryptoInvalidCiphertext)
+	}
+
+	return string(data[2 : 2+idLength]), data[2+idLength:], nil
+}
+
+// NewEncryptWriter returns io.WriteCloser that encrypts the data written to it with the primary key in chunks of CryptoStreamChunkSize,
+// and writes the result to w. Close must be called to write the final chunk; it does not close w.
+//
+// Each chunk is sealed with AES-256-GCM under a key derived from the ring key and a random salt, and its nonce is
+// the chunk counter with a flag marking the final chunk, so that reordering, truncation and extension are detected.
+func (r *SymmetricKeyRing) NewEncryptWriter(w io.Writer) (io.WriteCloser, error) {
+	id, key, err := r.primaryKey()
+	if err != nil {
+		return nil, err
+	}
+
+	salt := make([]byte, cryptoStreamSaltSize)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, fmt.Errorf("rand.Reader: %w", err)
+	}
+
+	header := append(append([]byte{cryptoStreamVersion, byte(len(id))}, id...), salt...)
+
+	stream, err := Crypto.newStream(key, salt, header)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := w.Write(header); err != nil {
+		return nil, fmt.Errorf("(io.Writer).Write: %w", err)
+	}
+
+	return &cryptoEncryptWriter{cryptoStream: stream, w: w, buf: make([]byte, 0, CryptoStreamChunkSize)}, nil
+}
+
+// NewDecryptReader returns io.Reader that decrypts the stream written by the writer of NewEncryptWriter.
+// Read returns an error wrapping ErrCryptoInvalidCiphertext if the stream has been tampered with or truncated.
+func (r *SymmetricKeyRing) NewDecryptReader(rd io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(rd)
+
+	prefix := make([]byte, 2) // nolint: gomnd
+	if _, err := io.ReadFull(br, prefix); err != nil {
+		return nil, fmt.Errorf("header: %v: %w", err, ErrCryptoInvalidCiphertext)
+	}
+
+	rest := make([]byte, int(prefix[1])+cryptoStreamSaltSize)
+	if _, err := io.ReadFull(br, rest); err != nil {
+		return nil, fmt.Errorf("header: %v: %w", err, ErrCryptoInvalidCiphertext)
+	}
+
+	header := append(prefix, rest...) // nolint: gocritic
+
+	id, salt, err := Crypto.unmarshalKeyIDHeader(header, cryptoStreamVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := r.key(id)
+	if err != nil {
+		return nil, err
+	}
+
+	stream, err := Crypto.newStream(key, salt, header)
+	if err != nil {
+		return nil, err
+	}
+
+	return &cryptoDecryptReader{cryptoStream: stream, r: br}, nil
+}
+
+type cryptoStream struct {
+	aead    cipher.AEAD
+	header  []byte
+	counter uint64
+}
+
+func (cryptoUtility) newStream(key, salt, header []byte) (*cryptoStream, error) {
+	chunkKey, err := Crypto.hkdfSHA256(key, salt, cryptoStreamInfo, CryptoSymmetricKeySize)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := Crypto.newAESGCM(chunkKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &cryptoStream{aead: aead, header: header}, nil
+}
+
+func (s *cryptoStream) nonce(last bool) []byte {
+	nonce := make([]byte, cryptoStreamNonceIndex+1)
+	binary.BigEndian.PutUint64(nonce[cryptoStreamNonceIndex-8:cryptoStreamNonceIndex], s.counter)
+	if last {
+		nonce[cryptoStreamNonceIndex] = cryptoStreamLastChunk
+	}
+
+	s.counter++
+
+	return nonce
+}
+
+type cryptoEncryptWriter struct {
+	*cryptoStream
+	w      io.Writer
+	buf    []byte
+	closed bool
+}
+
+func (w *cryptoEncryptWriter) Write(p []byte) (n int, err error) {
+	if w.closed {
+		return 0, io.ErrClosedPipe
+	}
+
+	for len(p) > 0 {
+		// NOTE: A full buffer is flushed only when more data follows, because the final chunk must be sealed as such on Close.
+		if len(w.buf) == CryptoStreamChunkSize {
+			if err := w.flush(false); err != nil {
+				return n, err
+			}
+		}
+
+		written := copy(w.buf[len(w.buf):CryptoStreamChunkSize], p)
+		w.buf = w.buf[:len(w.buf)+written]
+		p = p[written:]
+		n += written
+	}
+
+	return n, nil
+}
+
+func (w *cryptoEncryptWriter) Close() error {
+	if w.closed {
+		return nil
+	}
+
+	w.closed = true
+
+	return w.flush(true)
+}
+
+func (w *cryptoEncryptWriter) flush(last bool) error {
+	chunk := w.aead.Seal(nil, w.nonce(last), w.buf, w.header)
+	w.buf = w.buf[:0]
+
+	if _, err := w.w.Write(chunk); err != nil {
+		return fmt.Errorf("(io.Writer).Write: %w", err)
+	}
+
+	return nil
+}
+
+type cryptoDecryptReader struct {
+	*cryptoStream
+	r    *bufio.Reader
+	buf  []byte
+	done bool
+}
+
+func (r *cryptoDecryptReader) Read(p []byte) (n int, err error) {
+	for len(r.buf) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+
+		if err := r.next(); err != nil {
+			return 0, err
+		}
+	}
+
+	n = copy(p, r.buf)
+	r.buf = r.buf[n:]
+
+	return n, nil
+}
+
+func (r *cryptoDecryptReader) next() error {
+	const overhead = 16
+
+	chunk := make([]byte, CryptoStreamChunkSize+overhead)
+
+	n, err := io.ReadFull(r.r, chunk)
+	switch {
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		r.done = true
+	case err != nil:
+		return fmt.Errorf("(io.Reader).Read: %w", err)
+	default:
+		if _, err := r.r.Peek(1); errors.Is(err, io.EOF) {
+			r.done = true
+		}
+	}
+
+	plaintext, err := r.aead.Open(chunk[:0], r.nonce(r.done), chunk[:n], r.header)
+	if err != nil {
+		return fmt.Errorf("chunk=%d: (cipher.AEAD).Open: %v: %w", r.counter-1, err, ErrCryptoInvalidCiphertext)
+	}
+
+	r.buf = plaintext
+
+	return nil
+}
@@ -0,0 +1,220 @@
+// nolint: testpackage
+package nits
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/nitpickers/nits.go/nitstest"
+)
+
+func testNewSymmetricKeyRing(t *testing.T, ids ...string) *SymmetricKeyRing {
+	t.Helper()
+
+	ring := Crypto.NewSymmetricKeyRing()
+	for _, id := range ids {
+		if err := ring.Rotate(id); err != nil {
+			t.Fatalf("(*SymmetricKeyRing).Rotate: %v", err)
+		}
+	}
+
+	return ring
+}
+
+func TestSymmetricKeyRing_Add(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		id      string
+		key     []byte
+		wantErr error
+	}{
+		{"success()", "id", make([]byte, CryptoSymmetricKeySize), nil},
+		{"failure(id,empty)", "", make([]byte, CryptoSymmetricKeySize), ErrCryptoInvalidKey},
+		{"failure(id,long)", string(make([]byte, 256)), make([]byte, CryptoSymmetricKeySize), ErrCryptoInvalidKey},
+		{"failure(key)", "id", make([]byte, 16), ErrCryptoInvalidKey},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ring := Crypto.NewSymmetricKeyRing()
+			if err := ring.Add(tt.id, tt.key); !errors.Is(err, tt.wantErr) {
+				t.Errorf("(*SymmetricKeyRing).Add() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSymmetricKeyRing_Seal_Open(t *testing.T) {
+	t.Parallel()
+
+	ring := testNewSymmetricKeyRing(t, "2022-01")
+	nitstest.FailIfNotEqual(t, "2022-01", ring.Primary())
+	old, err := ring.Seal([]byte("old"), []byte("ad"))
+	if err != nil {
+		t.Fatalf("(*SymmetricKeyRing).Seal: %v", err)
+	}
+
+	if err := ring.Rotate("2022-02"); err != nil {
+		t.Fatalf("(*SymmetricKeyRing).Rotate: %v", err)
+	}
+	nitstest.FailIfNotEqual(t, "2022-02", ring.Primary())
+	current, err := ring.Seal([]byte("current"), nil)
+	if err != nil {
+		t.Fatalf("(*SymmetricKeyRing).Seal: %v", err)
+	}
+
+	t.Run("success(retired)", func(t *testing.T) {
+		t.Parallel()
+		actual, err := ring.Open(old, []byte("ad"))
+		if err != nil {
+			t.Fatalf("(*SymmetricKeyRing).Open: %v", err)
+		}
+		nitstest.FailIfNotBytesEqual(t, []byte("old"), actual)
+	})
+
+	t.Run("success(primary)", func(t *testing.T) {
+		t.Parallel()
+		actual, err := ring.Open(current, nil)
+		if err != nil {
+			t.Fatalf("(*SymmetricKeyRing).Open: %v", err)
+		}
+		nitstest.FailIfNotBytesEqual(t, []byte("current"), actual)
+	})
+
+	t.Run("failure(additionalData)", func(t *testing.T) {
+		t.Parallel()
+		if _, err := ring.Open(old, []byte("other")); !errors.Is(err, ErrCryptoInvalidCiphertext) {
+			t.Errorf("err != ErrCryptoInvalidCiphertext: %v", err)
+		}
+	})
+
+	t.Run("failure(ErrCryptoKeyNotFound)", func(t *testing.T) {
+		t.Parallel()
+		if _, err := testNewSymmetricKeyRing(t, "other").Open(current, nil); !errors.Is(err, ErrCryptoKeyNotFound) {
+			t.Errorf("err != ErrCryptoKeyNotFound: %v", err)
+		}
+	})
+
+	t.Run("failure(ErrCryptoInvalidCiphertext)", func(t *testing.T) {
+		t.Parallel()
+		for _, envelope := range [][]byte{nil, {2}, {1, 10}, append([]byte{1, 7}, "2022-02"...)} {
+			if _, err := ring.Open(envelope, nil); !errors.Is(err, ErrCryptoInvalidCiphertext) {
+				t.Errorf("envelope=%v: err != ErrCryptoInvalidCiphertext: %v", envelope, err)
+			}
+		}
+	})
+
+	t.Run("failure(ErrCryptoNoPrimaryKey)", func(t *testing.T) {
+		t.Parallel()
+		ring := testNewSymmetricKeyRing(t, "id")
+		ring.Remove("id")
+		if _, err := ring.Seal(nil, nil); !errors.Is(err, ErrCryptoNoPrimaryKey) {
+			t.Errorf("err != ErrCryptoNoPrimaryKey: %v", err)
+		}
+		if _, err := ring.NewEncryptWriter(io.Discard); !errors.Is(err, ErrCryptoNoPrimaryKey) {
+			t.Errorf("err != ErrCryptoNoPrimaryKey: %v", err)
+		}
+		if err := ring.SetPrimary("id"); !errors.Is(err, ErrCryptoKeyNotFound) {
+			t.Errorf("err != ErrCryptoKeyNotFound: %v", err)
+		}
+	})
+}
+
+func TestSymmetricKeyRing_NewEncryptWriter_NewDecryptReader(t *testing.T) {
+	t.Parallel()
+
+	ring := testNewSymmetricKeyRing(t, "id")
+	tests := []struct {
+		name string
+		size int
+	}{
+		{"success(empty)", 0},
+		{"success(small)", 100},
+		{"success(chunk)", CryptoStreamChunkSize},
+		{"success(chunks)", 3*CryptoStreamChunkSize + 1},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			plaintext := bytes.Repeat([]byte{'a'}, tt.size)
+			encrypted := bytes.NewBuffer(nil)
+			w, err := ring.NewEncryptWriter(encrypted)
+			if err != nil {
+				t.Fatalf("(*SymmetricKeyRing).NewEncryptWriter: %v", err)
+			}
+			// write in odd-sized pieces to cross chunk boundaries
+			for rest := plaintext; len(rest) > 0; {
+				n := 1000
+				if n > len(rest) {
+					n = len(rest)
+				}
+				if _, err := w.Write(rest[:n]); err != nil {
+					t.Fatalf("(io.Writer).Write: %v", err)
+				}
+				rest = rest[n:]
+			}
+			if err := w.Close(); err != nil {
+				t.Fatalf("(io.Closer).Close: %v", err)
+			}
+			if _, err := w.Write([]byte("after close")); err == nil {
+				t.Errorf("err == nil")
+			}
+
+			r, err := ring.NewDecryptReader(bytes.NewReader(encrypted.Bytes()))
+			if err != nil {
+				t.Fatalf("(*SymmetricKeyRing).NewDecryptReader: %v", err)
+			}
+			actual, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatalf("io.ReadAll: %v", err)
+			}
+			nitstest.FailIfNotBytesEqual(t, plaintext, actual)
+
+			truncated := encrypted.Bytes()[:encrypted.Len()-1]
+			r, err = ring.NewDecryptReader(bytes.NewReader(truncated))
+			if err != nil {
+				t.Fatalf("(*SymmetricKeyRing).NewDecryptReader: %v", err)
+			}
+			if _, err := io.ReadAll(r); !errors.Is(err, ErrCryptoInvalidCiphertext) {
+				t.Errorf("err != ErrCryptoInvalidCiphertext: %v", err)
+			}
+		})
+	}
+
+	t.Run("failure(truncated at chunk boundary)", func(t *testing.T) {
+		t.Parallel()
+		encrypted := bytes.NewBuffer(nil)
+		w, _ := ring.NewEncryptWriter(encrypted)
+		_, _ = w.Write(make([]byte, 2*CryptoStreamChunkSize))
+		_ = w.Close()
+		header := 2 + len("id") + cryptoStreamSaltSize
+		r, _ := ring.NewDecryptReader(bytes.NewReader(encrypted.Bytes()[:header+CryptoStreamChunkSize+16]))
+		if _, err := io.ReadAll(r); !errors.Is(err, ErrCryptoInvalidCiphertext) {
+			t.Errorf("err != ErrCryptoInvalidCiphertext: %v", err)
+		}
+	})
+
+	t.Run("failure(header)", func(t *testing.T) {
+		t.Parallel()
+		for _, data := range [][]byte{nil, {2, 2, 'i'}, append([]byte{1, 2, 'i', 'd'}, make([]byte, cryptoStreamSaltSize)...)} {
+			if _, err := ring.NewDecryptReader(bytes.NewReader(data)); !errors.Is(err, ErrCryptoInvalidCiphertext) {
+				t.Errorf("data=%v: err != ErrCryptoInvalidCiphertext: %v", data, err)
+			}
+		}
+		if _, err := ring.NewDecryptReader(bytes.NewReader(append([]byte{2, 2, 'n', 'o'}, make([]byte, cryptoStreamSaltSize)...))); !errors.Is(err, ErrCryptoKeyNotFound) {
+			t.Errorf("err != ErrCryptoKeyNotFound: %v", err)
+		}
+	})
+
+	t.Run("failure(io.Writer)", func(t *testing.T) {
+		t.Parallel()
+		if _, err := ring.NewEncryptWriter(&testResponseWriter{err: nitstest.ErrTestError}); !errors.Is(err, nitstest.ErrTestError) {
+			t.Errorf("err != nitstest.ErrTestError: %v", err)
+		}
+	})
+}
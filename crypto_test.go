@@ -7,6 +7,7 @@ import (
 	"crypto/ecdsa"
 	"crypto/elliptic"
 	"crypto/rand"
+	"encoding/json"
 	"errors"
 	"io"
 	"testing"
@@ -58,11 +59,23 @@ func TestCryptoUtility_GenerateKey(t *testing.T) {
 		}
 	})
 
+	t.Run("success(CryptoX25519)", func(t *testing.T) {
+		if _, err := Crypto.GenerateKey(CryptoX25519); err != nil {
+			t.Error(err)
+		}
+	})
+
 	t.Run("error(NoSuchAlgorithm)", func(t *testing.T) {
 		if _, err := Crypto.GenerateKey("NoSuchAlgorithm"); !errors.Is(err, ErrCryptoNoSuchCryptographicAlgorithm) {
 			t.Error(err)
 		}
 	})
+
+	t.Run("error(WeakAlgorithm)", func(t *testing.T) {
+		if _, err := Crypto.GenerateKey("rsa512"); !errors.Is(err, ErrCryptoWeakCryptographicAlgorithm) {
+			t.Error(err)
+		}
+	})
 }
 
 func Test_cryptoUtility_GenerateKeyBytes(t *testing.T) {
@@ -93,7 +106,7 @@ func Test_cryptoUtility_generateKeyBytes(t *testing.T) {
 	t.Parallel()
 	type args struct {
 		algorithm       CryptographicAlgorithm
-		generateKeyFunc func(algorithm string) (crypto.PrivateKey, error)
+		generateKeyFunc func(algorithm CryptographicAlgorithm) (crypto.PrivateKey, error)
 	}
 	tests := []struct {
 		name    string
@@ -101,8 +114,8 @@ func Test_cryptoUtility_generateKeyBytes(t *testing.T) {
 		wantErr bool
 	}{
 		{"success()", args{"rsa2048", Crypto.GenerateKey}, false},
-		{"failure(generateKeyFunc)", args{"rsa2048", func(algorithm string) (crypto.PrivateKey, error) { return nil, io.EOF }}, true},
-		{"failure(generateKeyFunc)", args{"rsa2048", func(algorithm string) (crypto.PrivateKey, error) { return &dsa.PrivateKey{}, nil }}, true},
+		{"failure(generateKeyFunc)", args{"rsa2048", func(algorithm CryptographicAlgorithm) (crypto.PrivateKey, error) { return nil, io.EOF }}, true},
+		{"failure(generateKeyFunc)", args{"rsa2048", func(algorithm CryptographicAlgorithm) (crypto.PrivateKey, error) { return &dsa.PrivateKey{}, nil }}, true},
 	}
 	for _, tt := range tests {
 		tt := tt
@@ -115,3 +128,102 @@ func Test_cryptoUtility_generateKeyBytes(t *testing.T) {
 		})
 	}
 }
+
+func TestCryptoUtility_ParseCryptographicAlgorithm(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		s       string
+		want    CryptographicAlgorithm
+		wantErr error
+	}{
+		{"success(rsa2048)", "rsa2048", CryptoRSA2048, nil},
+		{"success(RSA-2048)", "RSA-2048", CryptoRSA2048, nil},
+		{"success(rsa_3072)", "rsa_3072", "rsa3072", nil},
+		{"success(RS256)", "RS256", CryptoRSA2048, nil},
+		{"success(P-256)", "P-256", CryptoECDSA256, nil},
+		{"success(prime256v1)", "prime256v1", CryptoECDSA256, nil},
+		{"success(ES256)", "ES256", CryptoECDSA256, nil},
+		{"success(secp384r1)", "secp384r1", CryptoECDSA384, nil},
+		{"success(ECDSA384)", "ECDSA384", CryptoECDSA384, nil},
+		{"success( Ed25519 )", " Ed25519 ", CryptoEd25519, nil},
+		{"success(EdDSA)", "EdDSA", CryptoEd25519, nil},
+		{"success(X25519)", "X25519", CryptoX25519, nil},
+		{"failure(rsa512)", "rsa512", "", ErrCryptoWeakCryptographicAlgorithm},
+		{"failure(RSA-1024)", "RSA-1024", "", ErrCryptoWeakCryptographicAlgorithm},
+		{"failure(rsa16392)", "rsa16392", "", ErrCryptoCryptographicAlgorithmExceedsPolicy},
+		{"failure(rsa32768)", "rsa32768", "", ErrCryptoCryptographicAlgorithmExceedsPolicy},
+		{"failure()", "", "", ErrCryptoNoSuchCryptographicAlgorithm},
+		{"failure(rsa)", "rsa", "", ErrCryptoNoSuchCryptographicAlgorithm},
+		{"failure(rsa-x)", "rsa-x", "", ErrCryptoNoSuchCryptographicAlgorithm},
+		{"failure(rsa02048)", "rsa02048", "", ErrCryptoNoSuchCryptographicAlgorithm},
+		{"failure(rsa2049)", "rsa2049", "", ErrCryptoNoSuchCryptographicAlgorithm},
+		{"failure(rsa1028)", "rsa1028", "", ErrCryptoNoSuchCryptographicAlgorithm},
+		{"failure(dsa)", "dsa", "", ErrCryptoNoSuchCryptographicAlgorithm},
+		{"failure(P-521)", "P-521", "", ErrCryptoNoSuchCryptographicAlgorithm},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := Crypto.ParseCryptographicAlgorithm(tt.s)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Crypto.ParseCryptographicAlgorithm() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Crypto.ParseCryptographicAlgorithm() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCryptographicAlgorithm_UnmarshalText(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success()", func(t *testing.T) {
+		t.Parallel()
+		var config struct {
+			Algorithm CryptographicAlgorithm `json:"algorithm"`
+		}
+
+		if err := json.Unmarshal([]byte(`{"algorithm":"P-384"}`), &config); err != nil {
+			t.Fatal(err)
+		}
+
+		if config.Algorithm != CryptoECDSA384 || config.Algorithm.String() != "ecdsa384" {
+			t.Errorf("unexpected algorithm: %s", config.Algorithm)
+		}
+
+		if data, _ := json.Marshal(config); string(data) != `{"algorithm":"ecdsa384"}` {
+			t.Errorf("unexpected JSON: %s", data)
+		}
+	})
+
+	t.Run("failure()", func(t *testing.T) {
+		t.Parallel()
+		var algorithm CryptographicAlgorithm
+		if err := algorithm.UnmarshalText([]byte("rsa512")); !errors.Is(err, ErrCryptoWeakCryptographicAlgorithm) {
+			t.Errorf("err != ErrCryptoWeakCryptographicAlgorithm: %v", err)
+		}
+	})
+}
+
+func TestCryptoUtility_SupportedCryptographicAlgorithms(t *testing.T) {
+	t.Parallel()
+
+	for _, algorithm := range Crypto.SupportedCryptographicAlgorithms() {
+		if err := algorithm.Validate(); err != nil {
+			t.Error(err)
+		}
+	}
+
+	policy := CryptographicAlgorithmPolicy{MinRSABits: 3072}
+	if err := policy.Check(CryptoRSA2048); !errors.Is(err, ErrCryptoWeakCryptographicAlgorithm) {
+		t.Errorf("err != ErrCryptoWeakCryptographicAlgorithm: %v", err)
+	}
+
+	if err := policy.Check(CryptoRSA8192); err != nil {
+		t.Error(err)
+	}
+}
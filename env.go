@@ -31,6 +31,17 @@ func (envUtility) GetOrDefaultString(env, defaultValue string) (value string) {
 	return valueString
 }
 
+// GetOrDefaultSecret returns the value of the environment variable `env` as Secret if it is set, or `defaultValue` if it is not set.
+func (envUtility) GetOrDefaultSecret(env string, defaultValue Secret) (value Secret) {
+	valueString := os.Getenv(env)
+
+	if valueString == "" {
+		return defaultValue
+	}
+
+	return Crypto.NewSecretString(valueString)
+}
+
 // GetOrDefaultBool returns the value of the environment variable `env` if it is set, or `defaultValue` if it is not set.
 func (envUtility) GetOrDefaultBool(env string, defaultValue bool) (value bool) {
 	valueString := os.Getenv(env)
@@ -89,6 +100,16 @@ func (envUtility) GetString(env string) (value string, err error) {
 	return valueString, nil
 }
 
+// GetSecret returns the value of the environment variable `env` as Secret if it is set, or the error if it is not set.
+func (envUtility) GetSecret(env string) (value Secret, err error) {
+	valueString, err := Env.GetString(env)
+	if err != nil {
+		return Secret{}, err
+	}
+
+	return Crypto.NewSecretString(valueString), nil
+}
+
 // GetBool returns the value of the environment variable `env` if it is set, or the error if it is not set or invalid.
 func (envUtility) GetBool(env string) (value bool, err error) {
 	valueString := os.Getenv(env)
@@ -153,6 +153,55 @@ func TestGetString(t *testing.T) {
 	})
 }
 
+// nolint: paralleltest
+func TestGetSecret(t *testing.T) {
+	testEnvSuccessValue := "value"
+
+	t.Run("success()", func(t *testing.T) {
+		t.Setenv(testEnvKey, testEnvSuccessValue)
+		actual, err := nits.Env.GetSecret(testEnvKey)
+		if err != nil {
+			t.Error(err, "!=", nil)
+		}
+		if actual.RevealString() != testEnvSuccessValue {
+			t.Error()
+		}
+	})
+
+	t.Run("error()", func(t *testing.T) {
+		t.Setenv(testEnvKey, "")
+		actual, err := nits.Env.GetSecret(testEnvKey)
+		if err == nil {
+			t.Error()
+		}
+		if !actual.IsEmpty() {
+			t.Error()
+		}
+	})
+}
+
+// nolint: paralleltest
+func TestGetOrDefaultSecret(t *testing.T) {
+	testEnvDefaultValue := nits.Crypto.NewSecretString("defaultValue")
+	testEnvValue := "value"
+
+	t.Run("success()", func(t *testing.T) {
+		t.Setenv(testEnvKey, testEnvValue)
+		actual := nits.Env.GetOrDefaultSecret(testEnvKey, testEnvDefaultValue)
+		if actual.RevealString() != testEnvValue {
+			t.Error()
+		}
+	})
+
+	t.Run("success(default)", func(t *testing.T) {
+		t.Setenv(testEnvKey, "")
+		actual := nits.Env.GetOrDefaultSecret(testEnvKey, testEnvDefaultValue)
+		if actual.RevealString() != testEnvDefaultValue.RevealString() {
+			t.Error()
+		}
+	})
+}
+
 // nolint: paralleltest
 func TestGetBool(t *testing.T) {
 	testEnvSuccessValue := true
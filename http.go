@@ -26,6 +26,7 @@ func (httpUtility) TerminateHandlerFunc(preprocess http.HandlerFunc, shutdownCha
 }
 
 // ListenAndServe will start *http.Server and ignore http.ErrServerClosed on shutdown.
+// If server.TLSConfig has certificates or GetCertificate, such as CertificateReloader.TLSConfig, it serves HTTPS.
 // ListenAndServe expects to be used in conjunction with Shutdown:.
 //
 //	func startServer(ctx context.Context, server *http.Server) error {
@@ -49,6 +50,14 @@ func (httpUtility) TerminateHandlerFunc(preprocess http.HandlerFunc, shutdownCha
 //	}
 //
 func (httpUtility) ListenAndServe(server *http.Server) error {
+	if server.TLSConfig != nil && (len(server.TLSConfig.Certificates) > 0 || server.TLSConfig.GetCertificate != nil) {
+		if err := server.ListenAndServeTLS("", ""); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("ListenAndServeTLS: %w", err)
+		}
+
+		return nil
+	}
+
 	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 		return fmt.Errorf("ListenAndServe: %w", err)
 	}
@@ -179,3 +188,22 @@ func (httpUtility) BasicAuth(basicAuthUsers map[BasicAuthUsername]BasicAuthPassw
 		return false
 	}
 }
+
+// BasicAuthPasswordHash is the same as BasicAuth, except that the passwords are PHC strings returned by Password.Hash and are checked by Password.Verify.
+func (httpUtility) BasicAuthPasswordHash(basicAuthUsers map[BasicAuthUsername]string) func(r *http.Request) bool {
+	return func(r *http.Request) bool {
+		requestUsername, requestPassword, ok := r.BasicAuth()
+		if !ok {
+			return false
+		}
+
+		phc, ok := basicAuthUsers[requestUsername]
+		if !ok {
+			return false
+		}
+
+		_, err := Password.Verify(requestPassword, phc)
+
+		return err == nil
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"syscall"
 	"testing"
@@ -354,3 +355,37 @@ func Test_httpUtility_BasicAuth(t *testing.T) {
 		})
 	}
 }
+
+func Test_httpUtility_BasicAuthPasswordHash(t *testing.T) {
+	t.Parallel()
+
+	phc, err := testPasswordPolicy(PasswordArgon2id).Hash("password")
+	if err != nil {
+		t.Fatalf("(PasswordPolicy).Hash: %v", err)
+	}
+	basicAuth := HTTP.BasicAuthPasswordHash(map[BasicAuthUsername]string{"user": phc})
+
+	tests := []struct {
+		name     string
+		username string
+		password string
+		noAuth   bool
+		want     bool
+	}{
+		{"success()", "user", "password", false, true},
+		{"failure(password)", "user", "wrong", false, false},
+		{"failure(username)", "other", "password", false, false},
+		{"failure(NoAuth)", "", "", true, false},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			r := httptest.NewRequest("GET", "/", nil)
+			if !tt.noAuth {
+				r.SetBasicAuth(tt.username, tt.password)
+			}
+			nitstest.FailIfNotEqual(t, tt.want, basicAuth(r))
+		})
+	}
+}
@@ -0,0 +1,439 @@
+package nits
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrIDInvalidFormat invalid ID format.
+	ErrIDInvalidFormat = errors.New("invalid ID format")
+
+	// ErrIDMonotonicOverflow monotonic ID overflowed within the same millisecond.
+	ErrIDMonotonicOverflow = errors.New("monotonic ID overflowed within the same millisecond")
+)
+
+// IDEncoding is the alphabet of tokens generated by ID.NewToken.
+type IDEncoding = string
+
+const (
+	// IDEncodingBase32 is the lowercase alphabet of RFC 4648 base32.
+	IDEncodingBase32 IDEncoding = "abcdefghijklmnopqrstuvwxyz234567"
+	// IDEncodingBase58 is the Bitcoin alphabet of base58, which excludes 0, O, I and l.
+	IDEncodingBase58 IDEncoding = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+	// IDEncodingBase62 is the alphabet of base62.
+	IDEncodingBase62 IDEncoding = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+	// idEncodingCrockford is Crockford's base32 used by ULID.
+	idEncodingCrockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+	idULIDLength        = 26
+	idUUIDLength        = 36
+)
+
+// idUtility is an empty structure that is prepared only for creating methods.
+type idUtility struct{}
+
+// ID is an entity that allows the methods of idUtility to be executed from outside the package without initializing idUtility.
+// nolint: gochecknoglobals
+var ID idUtility
+
+// nolint: gochecknoglobals
+var idDefaultGenerator = ID.NewGenerator()
+
+// UUID is a UUID of RFC 9562.
+type UUID [16]byte
+
+// String returns the canonical form of the UUID such as "0190163d-8694-739b-aea5-966c26f8ad91".
+func (u UUID) String() string {
+	buf := make([]byte, idUUIDLength)
+	hex.Encode(buf[0:8], u[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], u[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], u[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], u[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], u[10:])
+
+	return string(buf)
+}
+
+// Version returns the version of the UUID.
+func (u UUID) Version() int {
+	return int(u[6] >> 4) // nolint: gomnd
+}
+
+// Time returns the time embedded in UUIDv7. It returns the zero time for the other versions.
+func (u UUID) Time() time.Time {
+	if u.Version() != 7 { // nolint: gomnd
+		return time.Time{}
+	}
+
+	return time.UnixMilli(idUnixMilli(u[:]))
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (u UUID) MarshalText() ([]byte, error) {
+	return []byte(u.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (u *UUID) UnmarshalText(text []byte) error {
+	parsed, err := ID.ParseUUID(string(text))
+	if err != nil {
+		return err
+	}
+
+	*u = parsed
+
+	return nil
+}
+
+// ULID is a Universally Unique Lexicographically Sortable Identifier.
+type ULID [16]byte
+
+// String returns the 26 characters of Crockford's base32 such as "01ARZ3NDEKTSV4RRFFQ69G5FAV".
+func (u ULID) String() string {
+	return idEncode(u[:], idEncodingCrockford, idULIDLength)
+}
+
+// Time returns the time embedded in the ULID.
+func (u ULID) Time() time.Time {
+	return time.UnixMilli(idUnixMilli(u[:]))
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (u ULID) MarshalText() ([]byte, error) {
+	return []byte(u.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (u *ULID) UnmarshalText(text []byte) error {
+	parsed, err := ID.ParseULID(string(text))
+	if err != nil {
+		return err
+	}
+
+	*u = parsed
+
+	return nil
+}
+
+// IDGenerator generates UUIDs and ULIDs. It is safe for concurrent use.
+type IDGenerator struct {
+	mu        sync.Mutex
+	reader    io.Reader
+	now       func() time.Time
+	monotonic bool
+
+	uuid idMonotonicState
+	ulid idMonotonicState
+}
+
+// idMonotonicState is the previous ID of a kind in the monotonic mode.
+type idMonotonicState struct {
+	milli  int64
+	random [10]byte
+}
+
+// nolint: gochecknoglobals
+var (
+	// idUUIDv7RandomMask excludes the version and the variant bits from the random bits of UUIDv7.
+	idUUIDv7RandomMask = [10]byte{0x0f, 0xff, 0x3f, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+	idULIDRandomMask   = [10]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+)
+
+// IDGeneratorOption is an option for ID.NewGenerator.
+type IDGeneratorOption func(*IDGenerator)
+
+// WithMonotonic makes the IDs generated within the same millisecond strictly increase, by incrementing the random bits of the previous ID.
+// It also keeps the IDs increasing when the clock goes backward.
+func (idUtility) WithMonotonic() IDGeneratorOption {
+	return func(g *IDGenerator) { g.monotonic = true }
+}
+
+// WithRandomReader replaces crypto/rand.Reader. It is intended for tests.
+func (idUtility) WithRandomReader(r io.Reader) IDGeneratorOption {
+	return func(g *IDGenerator) { g.reader = r }
+}
+
+// WithClock replaces time.Now.
+func (idUtility) WithClock(now func() time.Time) IDGeneratorOption {
+	return func(g *IDGenerator) { g.now = now }
+}
+
+// NewGenerator returns *IDGenerator.
+func (idUtility) NewGenerator(opts ...IDGeneratorOption) *IDGenerator {
+	g := &IDGenerator{reader: rand.Reader, now: time.Now}
+	for _, opt := range opts {
+		opt(g)
+	}
+
+	return g
+}
+
+// NewUUIDv4 returns a random UUIDv4.
+func (g *IDGenerator) NewUUIDv4() (UUID, error) {
+	var u UUID
+	if _, err := io.ReadFull(g.reader, u[:]); err != nil {
+		return UUID{}, fmt.Errorf("io.ReadFull: %w", err)
+	}
+
+	idSetVersion(&u, 4) // nolint: gomnd
+
+	return u, nil
+}
+
+// NewUUIDv7 returns a UUIDv7, which is ordered by time in milliseconds.
+func (g *IDGenerator) NewUUIDv7() (UUID, error) {
+	var u UUID
+	if err := g.fill(u[:], &g.uuid, &idUUIDv7RandomMask); err != nil {
+		return UUID{}, err
+	}
+
+	idSetVersion(&u, 7) // nolint: gomnd
+
+	return u, nil
+}
+
+// NewULID returns a ULID.
+func (g *IDGenerator) NewULID() (ULID, error) {
+	var u ULID
+	if err := g.fill(u[:], &g.ulid, &idULIDRandomMask); err != nil {
+		return ULID{}, err
+	}
+
+	return u, nil
+}
+
+// fill fills the 48-bit timestamp in milliseconds and the 80 random bits.
+// In the monotonic mode, the random bits of the previous ID are incremented within the same millisecond,
+// skipping the bits out of the mask so that the version and the variant of UUIDv7 are never carried into.
+func (g *IDGenerator) fill(b []byte, state *idMonotonicState, mask *[10]byte) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	milli := g.now().UnixMilli()
+
+	if g.monotonic && milli <= state.milli {
+		milli = state.milli
+
+		next := state.random
+		if !idIncrement(next[:], mask[:]) {
+			return fmt.Errorf("unix milli=%d: %w", milli, ErrIDMonotonicOverflow)
+		}
+
+		state.random = next
+	} else {
+		if _, err := io.ReadFull(g.reader, state.random[:]); err != nil {
+			return fmt.Errorf("io.ReadFull: %w", err)
+		}
+
+		for i := range state.random {
+			state.random[i] &= mask[i]
+		}
+	}
+
+	state.milli = milli
+
+	var ts [8]byte
+	binary.BigEndian.PutUint64(ts[:], uint64(milli))
+	copy(b[:6], ts[2:])
+	copy(b[6:], state.random[:])
+
+	return nil
+}
+
+// idIncrement increments the bits of b in the mask as a big-endian number, and reports false on overflow.
+func idIncrement(b, mask []byte) bool {
+	carry := uint16(1)
+	for i := len(b) - 1; i >= 0 && carry > 0; i-- {
+		v := uint16(b[i]|^mask[i]) + carry
+		b[i] = byte(v) & mask[i]
+		carry = v >> 8 // nolint: gomnd
+	}
+
+	return carry == 0
+}
+
+func idSetVersion(u *UUID, version byte) {
+	u[6] = (u[6] & 0x0f) | version<<4
+	u[8] = (u[8] & 0x3f) | 0x80 // nolint: gomnd
+}
+
+func idUnixMilli(b []byte) int64 {
+	var ts [8]byte
+	copy(ts[2:], b[:6])
+
+	return int64(binary.BigEndian.Uint64(ts[:]))
+}
+
+// NewUUIDv4 returns a random UUIDv4.
+func (idUtility) NewUUIDv4() (UUID, error) {
+	return idDefaultGenerator.NewUUIDv4()
+}
+
+// NewUUIDv7 returns a UUIDv7. Use ID.NewGenerator with ID.WithMonotonic if the IDs generated within the same millisecond must be ordered.
+func (idUtility) NewUUIDv7() (UUID, error) {
+	return idDefaultGenerator.NewUUIDv7()
+}
+
+// NewULID returns a ULID. Use ID.NewGenerator with ID.WithMonotonic if the IDs generated within the same millisecond must be ordered.
+func (idUtility) NewULID() (ULID, error) {
+	return idDefaultGenerator.NewULID()
+}
+
+// ParseUUID returns UUID from the canonical form. Upper case is also accepted.
+// It returns ErrIDInvalidFormat if the variant is not the one of RFC 9562.
+func (idUtility) ParseUUID(s string) (UUID, error) {
+	if len(s) != idUUIDLength || s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+		return UUID{}, fmt.Errorf("uuid=%q: %w", s, ErrIDInvalidFormat)
+	}
+
+	var u UUID
+	if _, err := hex.Decode(u[:], []byte(s[0:8]+s[9:13]+s[14:18]+s[19:23]+s[24:])); err != nil {
+		return UUID{}, fmt.Errorf("uuid=%q: hex.Decode: %v: %w", s, err, ErrIDInvalidFormat)
+	}
+
+	if u[8]&0xc0 != 0x80 {
+		return UUID{}, fmt.Errorf("uuid=%q: variant: %w", s, ErrIDInvalidFormat)
+	}
+
+	return u, nil
+}
+
+// ParseULID returns ULID from the 26 characters of Crockford's base32. Lower case is also accepted.
+func (idUtility) ParseULID(s string) (ULID, error) {
+	if len(s) != idULIDLength {
+		return ULID{}, fmt.Errorf("ulid=%q: %w", s, ErrIDInvalidFormat)
+	}
+
+	b, err := idDecode(strings.ToUpper(s), idEncodingCrockford, len(ULID{}))
+	if err != nil {
+		return ULID{}, fmt.Errorf("ulid=%q: %w", s, err)
+	}
+
+	var u ULID
+	copy(u[:], b)
+
+	return u, nil
+}
+
+// NewToken returns a URL-safe random token that has the entropy of the size in bytes, in the encoding.
+// The length of the token is fixed for the encoding and the size, so it can be validated by ID.ParseToken.
+func (idUtility) NewToken(encoding IDEncoding, size int) (string, error) {
+	if size < 1 {
+		return "", fmt.Errorf("size=%d: %w", size, ErrIDInvalidFormat)
+	}
+
+	if err := idCheckEncoding(encoding); err != nil {
+		return "", err
+	}
+
+	b := make([]byte, size)
+	if _, err := io.ReadFull(rand.Reader, b); err != nil {
+		return "", fmt.Errorf("io.ReadFull: %w", err)
+	}
+
+	return idEncode(b, encoding, ID.TokenLength(encoding, size)), nil
+}
+
+// TokenLength returns the length of the tokens that ID.NewToken returns for the encoding and the size.
+// It returns 0 if the encoding or the size is invalid.
+func (idUtility) TokenLength(encoding IDEncoding, size int) int {
+	if size < 1 || idCheckEncoding(encoding) != nil {
+		return 0
+	}
+
+	limit := new(big.Int).Lsh(big.NewInt(1), uint(8*size))
+	base := big.NewInt(int64(len(encoding)))
+
+	length := 0
+	for n := big.NewInt(1); n.Cmp(limit) < 0; n.Mul(n, base) {
+		length++
+	}
+
+	return length
+}
+
+// ParseToken returns the random bytes of the token returned by ID.NewToken with the encoding and the size.
+func (idUtility) ParseToken(encoding IDEncoding, size int, token string) ([]byte, error) {
+	if size < 1 {
+		return nil, fmt.Errorf("size=%d: %w", size, ErrIDInvalidFormat)
+	}
+
+	if err := idCheckEncoding(encoding); err != nil {
+		return nil, err
+	}
+
+	if len(token) != ID.TokenLength(encoding, size) {
+		return nil, fmt.Errorf("length=%d: %w", len(token), ErrIDInvalidFormat)
+	}
+
+	return idDecode(token, encoding, size)
+}
+
+// idCheckEncoding checks that the alphabet has at least 2 characters and no duplicates, without which tokens cannot be encoded or decoded.
+func idCheckEncoding(alphabet string) error {
+	if len(alphabet) < 2 { // nolint: gomnd
+		return fmt.Errorf("encoding=%q: must have at least 2 characters: %w", alphabet, ErrIDInvalidFormat)
+	}
+
+	var seen [256]bool
+
+	for i := 0; i < len(alphabet); i++ {
+		if seen[alphabet[i]] {
+			return fmt.Errorf("encoding=%q: duplicate character=%q: %w", alphabet, alphabet[i], ErrIDInvalidFormat)
+		}
+
+		seen[alphabet[i]] = true
+	}
+
+	return nil
+}
+
+// idEncode encodes b as a big-endian number in the alphabet, left-padded to the length.
+func idEncode(b []byte, alphabet string, length int) string {
+	n := new(big.Int).SetBytes(b)
+	base := big.NewInt(int64(len(alphabet)))
+	mod := new(big.Int)
+
+	buf := make([]byte, length)
+	for i := length - 1; i >= 0; i-- {
+		n.DivMod(n, base, mod)
+		buf[i] = alphabet[mod.Int64()]
+	}
+
+	return string(buf)
+}
+
+// idDecode decodes s encoded by idEncode to the size in bytes.
+func idDecode(s, alphabet string, size int) ([]byte, error) {
+	n := new(big.Int)
+	base := big.NewInt(int64(len(alphabet)))
+
+	for i := 0; i < len(s); i++ {
+		digit := strings.IndexByte(alphabet, s[i])
+		if digit < 0 {
+			return nil, fmt.Errorf("character=%q: %w", s[i], ErrIDInvalidFormat)
+		}
+
+		n.Mul(n, base)
+		n.Add(n, big.NewInt(int64(digit)))
+	}
+
+	if n.BitLen() > 8*size {
+		return nil, fmt.Errorf("overflow: %w", ErrIDInvalidFormat)
+	}
+
+	return n.FillBytes(make([]byte, size)), nil
+}
@@ -0,0 +1,298 @@
+// nolint: testpackage
+package nits
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIDUtility_NewUUIDv4(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success()", func(t *testing.T) {
+		t.Parallel()
+		u, err := ID.NewUUIDv4()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if u.Version() != 4 || u[8]&0xc0 != 0x80 || !u.Time().IsZero() {
+			t.Errorf("unexpected UUID: %s", u)
+		}
+
+		parsed, err := ID.ParseUUID(strings.ToUpper(u.String()))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if parsed != u {
+			t.Errorf("parsed != u: %s != %s", parsed, u)
+		}
+	})
+
+	t.Run("failure()", func(t *testing.T) {
+		t.Parallel()
+		if _, err := ID.NewGenerator(ID.WithRandomReader(bytes.NewReader(nil))).NewUUIDv4(); err == nil {
+			t.Error("err == nil")
+		}
+	})
+}
+
+func TestIDUtility_NewUUIDv7(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success()", func(t *testing.T) {
+		t.Parallel()
+		now := time.UnixMilli(1718000000123)
+		g := ID.NewGenerator(ID.WithClock(func() time.Time { return now }), ID.WithRandomReader(bytes.NewReader(make([]byte, 10))))
+
+		u, err := g.NewUUIDv7()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if expect := "019000c7-9c7b-7000-8000-000000000000"; u.String() != expect {
+			t.Errorf("u != expect: %s != %s", u, expect)
+		}
+
+		if u.Version() != 7 || !u.Time().Equal(now) {
+			t.Errorf("unexpected UUID: %s %s", u, u.Time())
+		}
+	})
+
+	t.Run("success(monotonic)", func(t *testing.T) {
+		t.Parallel()
+		now := time.UnixMilli(1718000000123)
+		g := ID.NewGenerator(ID.WithMonotonic(), ID.WithClock(func() time.Time { return now }))
+
+		prev, _ := g.NewUUIDv7()
+		for i := 0; i < 1000; i++ {
+			if i == 500 {
+				// NOTE: the clock goes backward.
+				now = now.Add(-time.Second)
+			}
+
+			u, err := g.NewUUIDv7()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if u.String() <= prev.String() || u.Version() != 7 || u[8]&0xc0 != 0x80 {
+				t.Fatalf("not monotonic: %s <= %s", u, prev)
+			}
+
+			prev = u
+		}
+	})
+
+	t.Run("failure(ErrIDMonotonicOverflow)", func(t *testing.T) {
+		t.Parallel()
+		max := bytes.Repeat([]byte{0xff}, 10)
+		g := ID.NewGenerator(ID.WithMonotonic(), ID.WithClock(func() time.Time { return time.UnixMilli(1) }), ID.WithRandomReader(bytes.NewReader(max)))
+
+		if _, err := g.NewUUIDv7(); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := g.NewUUIDv7(); !errors.Is(err, ErrIDMonotonicOverflow) {
+			t.Errorf("err != ErrIDMonotonicOverflow: %v", err)
+		}
+	})
+}
+
+func TestIDUtility_NewULID(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success()", func(t *testing.T) {
+		t.Parallel()
+		u, err := ID.NewULID()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		parsed, err := ID.ParseULID(strings.ToLower(u.String()))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if parsed != u {
+			t.Errorf("parsed != u: %s != %s", parsed, u)
+		}
+	})
+
+	t.Run("success(spec)", func(t *testing.T) {
+		t.Parallel()
+		// NOTE: the example of the ULID specification.
+		u, err := ID.ParseULID("01ARZ3NDEKTSV4RRFFQ69G5FAV")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if expect := time.UnixMilli(1469922850259); !u.Time().Equal(expect) {
+			t.Errorf("u.Time() != expect: %s != %s", u.Time(), expect)
+		}
+	})
+
+	t.Run("success(monotonic)", func(t *testing.T) {
+		t.Parallel()
+		g := ID.NewGenerator(ID.WithMonotonic(), ID.WithClock(func() time.Time { return time.UnixMilli(1469922850259) }))
+
+		prev, _ := g.NewULID()
+		for i := 0; i < 1000; i++ {
+			u, err := g.NewULID()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if u.String() <= prev.String() {
+				t.Fatalf("not monotonic: %s <= %s", u, prev)
+			}
+
+			prev = u
+		}
+	})
+}
+
+func TestIDUtility_ParseUUID(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		s       string
+		wantErr bool
+	}{
+		{"success()", "0190163d-8694-739b-aea5-966c26f8ad91", false},
+		{"failure(empty)", "", true},
+		{"failure(short)", "0190163d-8694-739b-aea5-966c26f8ad9", true},
+		{"failure(separator)", "0190163d_8694-739b-aea5-966c26f8ad91", true},
+		{"failure(hex)", "0190163d-8694-739b-aea5-966c26f8ad9g", true},
+		{"failure(variant)", "0190163d-8694-739b-cea5-966c26f8ad91", true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var u UUID
+			if err := u.UnmarshalText([]byte(tt.s)); (err != nil) != tt.wantErr || (err != nil && !errors.Is(err, ErrIDInvalidFormat)) {
+				t.Errorf("u.UnmarshalText() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if text, _ := u.MarshalText(); !tt.wantErr && string(text) != tt.s {
+				t.Errorf("unexpected text: %s", text)
+			}
+		})
+	}
+}
+
+func TestIDUtility_ParseULID(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		s       string
+		wantErr bool
+	}{
+		{"success()", "01ARZ3NDEKTSV4RRFFQ69G5FAV", false},
+		{"failure(empty)", "", true},
+		{"failure(short)", "01ARZ3NDEKTSV4RRFFQ69G5FA", true},
+		{"failure(alphabet)", "01ARZ3NDEKTSV4RRFFQ69G5FAU", true},
+		{"failure(overflow)", "81ARZ3NDEKTSV4RRFFQ69G5FAV", true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var u ULID
+			if err := u.UnmarshalText([]byte(tt.s)); (err != nil) != tt.wantErr || (err != nil && !errors.Is(err, ErrIDInvalidFormat)) {
+				t.Errorf("u.UnmarshalText() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if text, _ := u.MarshalText(); !tt.wantErr && string(text) != tt.s {
+				t.Errorf("unexpected text: %s", text)
+			}
+		})
+	}
+}
+
+func TestIDUtility_NewToken(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		encoding IDEncoding
+		length   int
+	}{
+		{"success(IDEncodingBase32)", IDEncodingBase32, 52},
+		{"success(IDEncodingBase58)", IDEncodingBase58, 44},
+		{"success(IDEncodingBase62)", IDEncodingBase62, 43},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			token, err := ID.NewToken(tt.encoding, 32)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(token) != tt.length || ID.TokenLength(tt.encoding, 32) != tt.length {
+				t.Errorf("len(token) != %d: %s", tt.length, token)
+			}
+
+			if _, err := ID.ParseToken(tt.encoding, 32, token); err != nil {
+				t.Error(err)
+			}
+
+			if _, err := ID.ParseToken(tt.encoding, 32, token[1:]+"!"); !errors.Is(err, ErrIDInvalidFormat) {
+				t.Errorf("err != ErrIDInvalidFormat: %v", err)
+			}
+
+			if _, err := ID.ParseToken(tt.encoding, 32, token[1:]); !errors.Is(err, ErrIDInvalidFormat) {
+				t.Errorf("err != ErrIDInvalidFormat: %v", err)
+			}
+		})
+	}
+
+	t.Run("success(leading zeros)", func(t *testing.T) {
+		t.Parallel()
+		if b, err := ID.ParseToken(IDEncodingBase58, 2, "112"); err != nil || !bytes.Equal(b, []byte{0, 1}) {
+			t.Errorf("b=%v err=%v", b, err)
+		}
+	})
+
+	t.Run("failure(encoding)", func(t *testing.T) {
+		t.Parallel()
+		for _, encoding := range []IDEncoding{"", "a", "abca"} {
+			if _, err := ID.NewToken(encoding, 32); !errors.Is(err, ErrIDInvalidFormat) {
+				t.Errorf("encoding=%q: err != ErrIDInvalidFormat: %v", encoding, err)
+			}
+
+			if _, err := ID.ParseToken(encoding, 32, "aaaa"); !errors.Is(err, ErrIDInvalidFormat) {
+				t.Errorf("encoding=%q: err != ErrIDInvalidFormat: %v", encoding, err)
+			}
+
+			if length := ID.TokenLength(encoding, 32); length != 0 {
+				t.Errorf("encoding=%q: length != 0: %d", encoding, length)
+			}
+		}
+	})
+
+	t.Run("failure(size)", func(t *testing.T) {
+		t.Parallel()
+		for _, size := range []int{0, -1} {
+			if _, err := ID.NewToken(IDEncodingBase62, size); !errors.Is(err, ErrIDInvalidFormat) {
+				t.Errorf("size=%d: err != ErrIDInvalidFormat: %v", size, err)
+			}
+
+			if _, err := ID.ParseToken(IDEncodingBase62, size, ""); !errors.Is(err, ErrIDInvalidFormat) {
+				t.Errorf("size=%d: err != ErrIDInvalidFormat: %v", size, err)
+			}
+
+			if length := ID.TokenLength(IDEncodingBase62, size); length != 0 {
+				t.Errorf("size=%d: length != 0: %d", size, length)
+			}
+		}
+	})
+}
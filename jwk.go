@@ -0,0 +1,478 @@
+package nits
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+)
+
+var (
+	// ErrJOSEInvalidJWK invalid JWK.
+	ErrJOSEInvalidJWK = errors.New("invalid JWK")
+
+	// ErrJOSEKeyNotFound key not found.
+	ErrJOSEKeyNotFound = errors.New("key not found")
+)
+
+// joseUtility is an empty structure that is prepared only for creating methods.
+type joseUtility struct{}
+
+// JOSE is an entity that allows the methods of JOSEUtility to be executed from outside the package without initializing JOSEUtility.
+// nolint: gochecknoglobals
+var JOSE joseUtility
+
+const (
+	// JWKKeyTypeRSA kty for RSA keys.
+	JWKKeyTypeRSA = "RSA"
+	// JWKKeyTypeEC kty for ECDSA keys.
+	JWKKeyTypeEC = "EC"
+	// JWKKeyTypeOKP kty for Ed25519 keys.
+	JWKKeyTypeOKP = "OKP"
+
+	// JWKCurveP256 crv for ECDSA with p-256 curve.
+	JWKCurveP256 = "P-256"
+	// JWKCurveP384 crv for ECDSA with p-384 curve.
+	JWKCurveP384 = "P-384"
+	// JWKCurveEd25519 crv for Ed25519.
+	JWKCurveEd25519 = "Ed25519"
+
+	// ContentTypeJWKSet is the media type of JWKS.
+	ContentTypeJWKSet = "application/jwk-set+json"
+)
+
+// JWK is a JSON Web Key (RFC 7517). The key parameters are base64url-encoded without padding.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid,omitempty"`
+	Use       string `json:"use,omitempty"`
+	Algorithm string `json:"alg,omitempty"`
+
+	// EC and OKP
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+	Y     string `json:"y,omitempty"`
+
+	// RSA
+	N  string `json:"n,omitempty"`
+	E  string `json:"e,omitempty"`
+	P  string `json:"p,omitempty"`
+	Q  string `json:"q,omitempty"`
+	DP string `json:"dp,omitempty"`
+	DQ string `json:"dq,omitempty"`
+	QI string `json:"qi,omitempty"`
+
+	// private key of EC, OKP and RSA
+	D string `json:"d,omitempty"`
+}
+
+// JWKS is a JSON Web Key Set. It implements http.Handler and serves only the public parameters of its keys.
+type JWKS struct {
+	Keys []*JWK `json:"keys"`
+}
+
+// NewJWK returns *JWK from the passed RSA, ECDSA or Ed25519 private or public key.
+// The private parameters are included only if a private key is passed. KeyID is set to the RFC 7638 thumbprint.
+func (joseUtility) NewJWK(key interface{}) (*JWK, error) {
+	jwk, err := JOSE.newJWK(key)
+	if err != nil {
+		return nil, err
+	}
+
+	kid, err := jwk.Thumbprint()
+	if err != nil {
+		return nil, err
+	}
+
+	jwk.KeyID = kid
+
+	return jwk, nil
+}
+
+func (joseUtility) newJWK(key interface{}) (*JWK, error) {
+	encode := base64.RawURLEncoding.EncodeToString
+
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		if len(k.Primes) != 2 { // nolint: gomnd
+			return nil, fmt.Errorf("multi-prime RSA: %w", ErrCryptoUnsupportedKeyType)
+		}
+
+		p, q := k.Primes[0], k.Primes[1]
+		one := big.NewInt(1)
+		jwk, _ := JOSE.newJWK(&k.PublicKey)
+		jwk.D = encode(k.D.Bytes())
+		jwk.P = encode(p.Bytes())
+		jwk.Q = encode(q.Bytes())
+		jwk.DP = encode(new(big.Int).Mod(k.D, new(big.Int).Sub(p, one)).Bytes())
+		jwk.DQ = encode(new(big.Int).Mod(k.D, new(big.Int).Sub(q, one)).Bytes())
+		jwk.QI = encode(new(big.Int).ModInverse(q, p).Bytes())
+
+		return jwk, nil
+	case *rsa.PublicKey:
+		return &JWK{
+			KeyType: JWKKeyTypeRSA,
+			N:       encode(k.N.Bytes()),
+			E:       encode(big.NewInt(int64(k.E)).Bytes()),
+		}, nil
+	case *ecdsa.PrivateKey:
+		jwk, err := JOSE.newJWK(&k.PublicKey)
+		if err != nil {
+			return nil, err
+		}
+
+		jwk.D = encode(k.D.FillBytes(make([]byte, (k.Curve.Params().BitSize+7)/8))) // nolint: gomnd
+
+		return jwk, nil
+	case *ecdsa.PublicKey:
+		crv, err := JOSE.curveName(k.Curve)
+		if err != nil {
+			return nil, err
+		}
+
+		size := (k.Curve.Params().BitSize + 7) / 8 // nolint: gomnd
+
+		return &JWK{
+			KeyType: JWKKeyTypeEC,
+			Curve:   crv,
+			X:       encode(k.X.FillBytes(make([]byte, size))),
+			Y:       encode(k.Y.FillBytes(make([]byte, size))),
+		}, nil
+	case ed25519.PrivateKey:
+		jwk, _ := JOSE.newJWK(k.Public())
+		jwk.D = encode(k.Seed())
+
+		return jwk, nil
+	case ed25519.PublicKey:
+		return &JWK{
+			KeyType: JWKKeyTypeOKP,
+			Curve:   JWKCurveEd25519,
+			X:       encode(k),
+		}, nil
+	}
+
+	return nil, fmt.Errorf("%T: %w", key, ErrCryptoUnsupportedKeyType)
+}
+
+func (joseUtility) curveName(curve elliptic.Curve) (string, error) {
+	switch curve {
+	case elliptic.P256():
+		return JWKCurveP256, nil
+	case elliptic.P384():
+		return JWKCurveP384, nil
+	}
+
+	return "", fmt.Errorf("curve=%s: %w", curve.Params().Name, ErrCryptoUnsupportedKeyType)
+}
+
+// ParseJWK returns *JWK from the passed JSON.
+func (joseUtility) ParseJWK(data []byte) (*JWK, error) {
+	jwk := new(JWK)
+	if err := json.Unmarshal(data, jwk); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal: %v: %w", err, ErrJOSEInvalidJWK)
+	}
+
+	if _, err := jwk.Key(); err != nil {
+		return nil, err
+	}
+
+	return jwk, nil
+}
+
+// ParseJWKS returns *JWKS from the passed JSON.
+func (joseUtility) ParseJWKS(data []byte) (*JWKS, error) {
+	jwks := new(JWKS)
+	if err := json.Unmarshal(data, jwks); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal: %v: %w", err, ErrJOSEInvalidJWK)
+	}
+
+	for i, jwk := range jwks.Keys {
+		if jwk == nil {
+			return nil, fmt.Errorf("keys[%d]: null: %w", i, ErrJOSEInvalidJWK)
+		}
+
+		if _, err := jwk.Key(); err != nil {
+			return nil, fmt.Errorf("keys[%d]: %w", i, err)
+		}
+	}
+
+	return jwks, nil
+}
+
+// IsPrivate returns whether or not the JWK contains private key parameters.
+func (jwk *JWK) IsPrivate() bool {
+	return jwk.D != ""
+}
+
+// Public returns a copy of the JWK that contains only the public key parameters.
+func (jwk *JWK) Public() *JWK {
+	return &JWK{
+		KeyType:   jwk.KeyType,
+		KeyID:     jwk.KeyID,
+		Use:       jwk.Use,
+		Algorithm: jwk.Algorithm,
+		Curve:     jwk.Curve,
+		X:         jwk.X,
+		Y:         jwk.Y,
+		N:         jwk.N,
+		E:         jwk.E,
+	}
+}
+
+// Key returns *rsa.PrivateKey, *ecdsa.PrivateKey or ed25519.PrivateKey if the JWK contains private key parameters,
+// otherwise *rsa.PublicKey, *ecdsa.PublicKey or ed25519.PublicKey.
+func (jwk *JWK) Key() (interface{}, error) {
+	if jwk.IsPrivate() {
+		return jwk.PrivateKey()
+	}
+
+	return jwk.PublicKey()
+}
+
+// PublicKey returns the public key of the JWK.
+func (jwk *JWK) PublicKey() (crypto.PublicKey, error) {
+	switch jwk.KeyType {
+	case JWKKeyTypeRSA:
+		n, err := jwk.decodeInt("n", jwk.N)
+		if err != nil {
+			return nil, err
+		}
+
+		e, err := jwk.decodeInt("e", jwk.E)
+		if err != nil {
+			return nil, err
+		}
+
+		if !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("e: %w", ErrJOSEInvalidJWK)
+		}
+
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case JWKKeyTypeEC:
+		curve, err := jwk.curve()
+		if err != nil {
+			return nil, err
+		}
+
+		x, err := jwk.decodeInt("x", jwk.X)
+		if err != nil {
+			return nil, err
+		}
+
+		y, err := jwk.decodeInt("y", jwk.Y)
+		if err != nil {
+			return nil, err
+		}
+
+		if !curve.IsOnCurve(x, y) { // nolint: staticcheck
+			return nil, fmt.Errorf("x, y: point is not on curve: %w", ErrJOSEInvalidJWK)
+		}
+
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case JWKKeyTypeOKP:
+		if jwk.Curve != JWKCurveEd25519 {
+			return nil, fmt.Errorf("crv=%s: %w", jwk.Curve, ErrCryptoUnsupportedKeyType)
+		}
+
+		x, err := jwk.decode("x", jwk.X)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("x: length=%d: %w", len(x), ErrJOSEInvalidJWK)
+		}
+
+		return ed25519.PublicKey(x), nil
+	}
+
+	return nil, fmt.Errorf("kty=%s: %w", jwk.KeyType, ErrCryptoUnsupportedKeyType)
+}
+
+// PrivateKey returns the private key of the JWK.
+func (jwk *JWK) PrivateKey() (crypto.PrivateKey, error) {
+	if !jwk.IsPrivate() {
+		return nil, fmt.Errorf("d: no private key parameters: %w", ErrJOSEInvalidJWK)
+	}
+
+	pub, err := jwk.PublicKey()
+	if err != nil {
+		return nil, err
+	}
+
+	switch pub := pub.(type) {
+	case *rsa.PublicKey:
+		var params [3]*big.Int
+		for i, v := range [][2]string{{"d", jwk.D}, {"p", jwk.P}, {"q", jwk.Q}} {
+			if params[i], err = jwk.decodeInt(v[0], v[1]); err != nil {
+				return nil, err
+			}
+		}
+
+		priv := &rsa.PrivateKey{PublicKey: *pub, D: params[0], Primes: []*big.Int{params[1], params[2]}}
+		if err := priv.Validate(); err != nil {
+			return nil, fmt.Errorf("(*rsa.PrivateKey).Validate: %v: %w", err, ErrJOSEInvalidJWK)
+		}
+
+		priv.Precompute()
+
+		return priv, nil
+	case *ecdsa.PublicKey:
+		d, err := jwk.decodeInt("d", jwk.D)
+		if err != nil {
+			return nil, err
+		}
+
+		if x, y := pub.Curve.ScalarBaseMult(d.Bytes()); x.Cmp(pub.X) != 0 || y.Cmp(pub.Y) != 0 { // nolint: staticcheck
+			return nil, fmt.Errorf("d: does not match x, y: %w", ErrJOSEInvalidJWK)
+		}
+
+		return &ecdsa.PrivateKey{PublicKey: *pub, D: d}, nil
+	case ed25519.PublicKey:
+		seed, err := jwk.decode("d", jwk.D)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(seed) != ed25519.SeedSize {
+			return nil, fmt.Errorf("d: length=%d: %w", len(seed), ErrJOSEInvalidJWK)
+		}
+
+		priv := ed25519.NewKeyFromSeed(seed)
+		if !bytes.Equal(priv.Public().(ed25519.PublicKey), pub) { // nolint: forcetypeassert
+			return nil, fmt.Errorf("d: does not match x: %w", ErrJOSEInvalidJWK)
+		}
+
+		return priv, nil
+	}
+
+	return nil, fmt.Errorf("%T: %w", pub, ErrCryptoUnsupportedKeyType)
+}
+
+// Thumbprint returns the base64url-encoded RFC 7638 SHA-256 thumbprint of the JWK.
+func (jwk *JWK) Thumbprint() (string, error) {
+	var members interface{}
+
+	// NOTE: encoding/json sorts map keys, so the members are in lexicographic order as RFC 7638 requires.
+	switch jwk.KeyType {
+	case JWKKeyTypeRSA:
+		members = map[string]string{"e": jwk.E, "kty": jwk.KeyType, "n": jwk.N}
+	case JWKKeyTypeEC:
+		members = map[string]string{"crv": jwk.Curve, "kty": jwk.KeyType, "x": jwk.X, "y": jwk.Y}
+	case JWKKeyTypeOKP:
+		members = map[string]string{"crv": jwk.Curve, "kty": jwk.KeyType, "x": jwk.X}
+	default:
+		return "", fmt.Errorf("kty=%s: %w", jwk.KeyType, ErrCryptoUnsupportedKeyType)
+	}
+
+	data, err := json.Marshal(members)
+	if err != nil {
+		return "", fmt.Errorf("json.Marshal: %w", err)
+	}
+
+	digest, _ := Crypto.digest(crypto.SHA256, data)
+
+	return base64.RawURLEncoding.EncodeToString(digest), nil
+}
+
+func (jwk *JWK) curve() (elliptic.Curve, error) {
+	switch jwk.Curve {
+	case JWKCurveP256:
+		return elliptic.P256(), nil
+	case JWKCurveP384:
+		return elliptic.P384(), nil
+	}
+
+	return nil, fmt.Errorf("crv=%s: %w", jwk.Curve, ErrCryptoUnsupportedKeyType)
+}
+
+func (*JWK) decode(name, value string) ([]byte, error) {
+	if value == "" {
+		return nil, fmt.Errorf("%s: missing: %w", name, ErrJOSEInvalidJWK)
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("%s: base64.RawURLEncoding.DecodeString: %v: %w", name, err, ErrJOSEInvalidJWK)
+	}
+
+	return data, nil
+}
+
+func (jwk *JWK) decodeInt(name, value string) (*big.Int, error) {
+	data, err := jwk.decode(name, value)
+	if err != nil {
+		return nil, err
+	}
+
+	return new(big.Int).SetBytes(data), nil
+}
+
+// Add adds the JWK of the passed key to the JWKS and returns the JWK.
+func (jwks *JWKS) Add(key interface{}) (*JWK, error) {
+	jwk, err := JOSE.NewJWK(key)
+	if err != nil {
+		return nil, fmt.Errorf("JOSE.NewJWK: %w", err)
+	}
+
+	jwks.Keys = append(jwks.Keys, jwk)
+
+	return jwk, nil
+}
+
+// Lookup returns the JWK that has the passed key ID.
+func (jwks *JWKS) Lookup(kid string) (*JWK, error) {
+	for _, jwk := range jwks.Keys {
+		if jwk.KeyID == kid {
+			return jwk, nil
+		}
+	}
+
+	return nil, fmt.Errorf("kid=%s: %w", kid, ErrJOSEKeyNotFound)
+}
+
+// Public returns a copy of the JWKS that contains only the public key parameters.
+func (jwks *JWKS) Public() *JWKS {
+	public := &JWKS{Keys: make([]*JWK, 0, len(jwks.Keys))}
+	for _, jwk := range jwks.Keys {
+		public.Keys = append(public.Keys, jwk.Public())
+	}
+
+	return public
+}
+
+// PublicKeys returns the public keys of the JWKS indexed by key ID.
+func (jwks *JWKS) PublicKeys() (map[string]crypto.PublicKey, error) {
+	publicKeys := make(map[string]crypto.PublicKey, len(jwks.Keys))
+	for _, jwk := range jwks.Keys {
+		pub, err := jwk.PublicKey()
+		if err != nil {
+			return nil, fmt.Errorf("kid=%s: %w", jwk.KeyID, err)
+		}
+
+		publicKeys[jwk.KeyID] = pub
+	}
+
+	return publicKeys, nil
+}
+
+// ServeHTTP serves the public keys of the JWKS as application/jwk-set+json.
+func (jwks *JWKS) ServeHTTP(rw http.ResponseWriter, _ *http.Request) {
+	data, err := json.Marshal(jwks.Public())
+	if err != nil {
+		http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+
+		return
+	}
+
+	rw.Header().Set("Content-Type", ContentTypeJWKSet)
+	_, _ = rw.Write(data)
+}
@@ -0,0 +1,200 @@
+// nolint: testpackage
+package nits
+
+import (
+	"crypto/dsa" // nolint: staticcheck
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nitpickers/nits.go/nitstest"
+)
+
+// cf. https://datatracker.ietf.org/doc/html/rfc7638#section-3.1
+const testRFC7638JWKString = `{"kty":"RSA","n":"0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw","e":"AQAB","alg":"RS256","kid":"2011-04-29"}`
+
+func Test_joseUtility_NewJWK_ParseJWK(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name      string
+		algorithm CryptographicAlgorithm
+		kty       string
+	}{
+		{"success(CryptoRSA2048)", CryptoRSA2048, JWKKeyTypeRSA},
+		{"success(CryptoECDSA256)", CryptoECDSA256, JWKKeyTypeEC},
+		{"success(CryptoECDSA384)", CryptoECDSA384, JWKKeyTypeEC},
+		{"success(CryptoEd25519)", CryptoEd25519, JWKKeyTypeOKP},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			privateKey := Crypto.MustGenerateKey(Crypto.GenerateKey(tt.algorithm))
+			jwk, err := JOSE.NewJWK(privateKey)
+			if err != nil {
+				t.Fatalf("JOSE.NewJWK: %v", err)
+			}
+			nitstest.FailIfNotEqual(t, tt.kty, jwk.KeyType)
+			nitstest.FailIfNotEqual(t, true, jwk.IsPrivate())
+			nitstest.FailIfEqual(t, "", jwk.KeyID)
+
+			parsed, err := JOSE.ParseJWK(JSON.MustMarshal(jwk))
+			if err != nil {
+				t.Fatalf("JOSE.ParseJWK: %v", err)
+			}
+			parsedPrivateKey, err := parsed.PrivateKey()
+			if err != nil {
+				t.Fatalf("(*JWK).PrivateKey: %v", err)
+			}
+			nitstest.FailIfNotBytesEqual(t, JSON.MustMarshal(jwk), JSON.MustMarshal(testMustNewJWK(t, parsedPrivateKey)))
+
+			public := jwk.Public()
+			nitstest.FailIfNotEqual(t, false, public.IsPrivate())
+			nitstest.FailIfNotEqual(t, jwk.KeyID, public.KeyID)
+			if _, err := public.PrivateKey(); !errors.Is(err, ErrJOSEInvalidJWK) {
+				t.Errorf("err != ErrJOSEInvalidJWK: %v", err)
+			}
+			publicKey, err := public.PublicKey()
+			if err != nil {
+				t.Fatalf("(*JWK).PublicKey: %v", err)
+			}
+			signature, err := Crypto.Sign(parsedPrivateKey, []byte("message"))
+			if err != nil {
+				t.Fatalf("Crypto.Sign: %v", err)
+			}
+			if err := Crypto.Verify(publicKey, []byte("message"), signature); err != nil {
+				t.Errorf("Crypto.Verify: %v", err)
+			}
+		})
+	}
+
+	t.Run("failure(ErrCryptoUnsupportedKeyType)", func(t *testing.T) {
+		t.Parallel()
+		if _, err := JOSE.NewJWK(&dsa.PrivateKey{}); !errors.Is(err, ErrCryptoUnsupportedKeyType) {
+			t.Errorf("err != ErrCryptoUnsupportedKeyType: %v", err)
+		}
+	})
+}
+
+func testMustNewJWK(t *testing.T, key interface{}) *JWK {
+	t.Helper()
+
+	jwk, err := JOSE.NewJWK(key)
+	if err != nil {
+		t.Fatalf("JOSE.NewJWK: %v", err)
+	}
+
+	return jwk
+}
+
+func Test_JWK_Thumbprint(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success(RFC7638)", func(t *testing.T) {
+		t.Parallel()
+		jwk, err := JOSE.ParseJWK([]byte(testRFC7638JWKString))
+		if err != nil {
+			t.Fatalf("JOSE.ParseJWK: %v", err)
+		}
+		thumbprint, err := jwk.Thumbprint()
+		if err != nil {
+			t.Fatalf("(*JWK).Thumbprint: %v", err)
+		}
+		nitstest.FailIfNotEqual(t, "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs", thumbprint)
+	})
+
+	t.Run("failure(ErrCryptoUnsupportedKeyType)", func(t *testing.T) {
+		t.Parallel()
+		if _, err := (&JWK{KeyType: "oct"}).Thumbprint(); !errors.Is(err, ErrCryptoUnsupportedKeyType) {
+			t.Errorf("err != ErrCryptoUnsupportedKeyType: %v", err)
+		}
+	})
+}
+
+func Test_joseUtility_ParseJWK(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		data    string
+		wantErr error
+	}{
+		{"failure(json)", `{`, ErrJOSEInvalidJWK},
+		{"failure(kty)", `{"kty":"oct","k":"AA"}`, ErrCryptoUnsupportedKeyType},
+		{"failure(crv)", `{"kty":"EC","crv":"P-521","x":"AA","y":"AA"}`, ErrCryptoUnsupportedKeyType},
+		{"failure(x,missing)", `{"kty":"EC","crv":"P-256","y":"AA"}`, ErrJOSEInvalidJWK},
+		{"failure(x,y,NotOnCurve)", `{"kty":"EC","crv":"P-256","x":"AQ","y":"AQ"}`, ErrJOSEInvalidJWK},
+		{"failure(x,base64)", `{"kty":"OKP","crv":"Ed25519","x":"!"}`, ErrJOSEInvalidJWK},
+		{"failure(x,length)", `{"kty":"OKP","crv":"Ed25519","x":"AA"}`, ErrJOSEInvalidJWK},
+		{"failure(e)", `{"kty":"RSA","n":"AQ","e":"AQ"}`, ErrJOSEInvalidJWK},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if _, err := JOSE.ParseJWK([]byte(tt.data)); !errors.Is(err, tt.wantErr) {
+				t.Errorf("JOSE.ParseJWK() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_JWKS(t *testing.T) {
+	t.Parallel()
+
+	jwks := new(JWKS)
+	for _, algorithm := range []CryptographicAlgorithm{CryptoECDSA256, CryptoEd25519} {
+		if _, err := jwks.Add(Crypto.MustGenerateKey(Crypto.GenerateKey(algorithm))); err != nil {
+			t.Fatalf("(*JWKS).Add: %v", err)
+		}
+	}
+	if _, err := jwks.Add(&dsa.PrivateKey{}); !errors.Is(err, ErrCryptoUnsupportedKeyType) {
+		t.Errorf("err != ErrCryptoUnsupportedKeyType: %v", err)
+	}
+
+	recorder := httptest.NewRecorder()
+	jwks.ServeHTTP(recorder, httptest.NewRequest("GET", "/.well-known/jwks.json", nil))
+	nitstest.FailIfNotEqual(t, ContentTypeJWKSet, recorder.Header().Get("Content-Type"))
+	body, _ := io.ReadAll(recorder.Body)
+
+	parsed, err := JOSE.ParseJWKS(body)
+	if err != nil {
+		t.Fatalf("JOSE.ParseJWKS: %v", err)
+	}
+	nitstest.FailIfNotEqual(t, 2, len(parsed.Keys))
+	for _, jwk := range parsed.Keys {
+		if jwk.IsPrivate() {
+			t.Errorf("private key parameters are served: kid=%s", jwk.KeyID)
+		}
+	}
+
+	publicKeys, err := parsed.PublicKeys()
+	if err != nil {
+		t.Fatalf("(*JWKS).PublicKeys: %v", err)
+	}
+	for _, jwk := range jwks.Keys {
+		if _, ok := publicKeys[jwk.KeyID]; !ok {
+			t.Errorf("kid=%s: not found", jwk.KeyID)
+		}
+		if _, err := parsed.Lookup(jwk.KeyID); err != nil {
+			t.Errorf("(*JWKS).Lookup: %v", err)
+		}
+	}
+	if _, err := parsed.Lookup("NoSuchKeyID"); !errors.Is(err, ErrJOSEKeyNotFound) {
+		t.Errorf("err != ErrJOSEKeyNotFound: %v", err)
+	}
+
+	if _, err := JOSE.ParseJWKS([]byte(`{"keys":[null]}`)); !errors.Is(err, ErrJOSEInvalidJWK) {
+		t.Errorf("err != ErrJOSEInvalidJWK: %v", err)
+	}
+	if _, err := JOSE.ParseJWKS([]byte(`{"keys":[{"kty":"oct"}]}`)); !errors.Is(err, ErrCryptoUnsupportedKeyType) {
+		t.Errorf("err != ErrCryptoUnsupportedKeyType: %v", err)
+	}
+	if _, err := JOSE.ParseJWKS([]byte(`[`)); !errors.Is(err, ErrJOSEInvalidJWK) {
+		t.Errorf("err != ErrJOSEInvalidJWK: %v", err)
+	}
+	if err := json.Unmarshal(JSON.MustMarshal(parsed), new(JWKS)); err != nil {
+		t.Errorf("json.Unmarshal: %v", err)
+	}
+}
@@ -0,0 +1,268 @@
+package nits
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrJWSInvalidFormat invalid JWS format.
+	ErrJWSInvalidFormat = errors.New("invalid JWS format")
+
+	// ErrJWSUnsupportedAlgorithm unsupported JWS algorithm.
+	ErrJWSUnsupportedAlgorithm = errors.New("unsupported JWS algorithm")
+
+	// ErrJWSAlgorithmDoesNotMatchKey JWS algorithm does not match key.
+	ErrJWSAlgorithmDoesNotMatchKey = errors.New("JWS algorithm does not match key")
+)
+
+// JWSAlgorithm is an alias of string.
+type JWSAlgorithm = string
+
+const (
+	// JWSRS256 RSASSA-PKCS1-v1_5 using SHA-256.
+	JWSRS256 JWSAlgorithm = "RS256"
+	// JWSPS256 RSASSA-PSS using SHA-256 and MGF1 with SHA-256.
+	JWSPS256 JWSAlgorithm = "PS256"
+	// JWSES256 ECDSA using P-256 and SHA-256.
+	JWSES256 JWSAlgorithm = "ES256"
+	// JWSES384 ECDSA using P-384 and SHA-384.
+	JWSES384 JWSAlgorithm = "ES384"
+	// JWSEdDSA EdDSA using Ed25519.
+	JWSEdDSA JWSAlgorithm = "EdDSA"
+)
+
+// JWSHeader is the JOSE header of JWS.
+type JWSHeader struct {
+	Algorithm JWSAlgorithm `json:"alg"`
+	Type      string       `json:"typ,omitempty"`
+	KeyID     string       `json:"kid,omitempty"`
+	Critical  []string     `json:"crit,omitempty"`
+}
+
+// JOSEOption is an option for the methods of JOSE.
+type JOSEOption func(*joseOptions)
+
+type joseOptions struct {
+	// signing
+	algorithm JWSAlgorithm
+	keyID     string
+	typ       string
+
+	// verification
+	algorithms []JWSAlgorithm
+	issuer     string
+	audience   string
+	clockSkew  time.Duration
+	now        func() time.Time
+}
+
+func (joseUtility) newOptions(opts []JOSEOption) *joseOptions {
+	o := &joseOptions{now: time.Now}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// WithAlgorithm sets the JWS algorithm used for signing. By default, it is chosen from the key by JOSE.AlgorithmOf.
+func (joseUtility) WithAlgorithm(algorithm JWSAlgorithm) JOSEOption {
+	return func(o *joseOptions) { o.algorithm = algorithm }
+}
+
+// WithKeyID sets the "kid" header used for signing. By default, it is the RFC 7638 thumbprint of the key.
+func (joseUtility) WithKeyID(kid string) JOSEOption {
+	return func(o *joseOptions) { o.keyID = kid }
+}
+
+// WithType sets the "typ" header used for signing.
+func (joseUtility) WithType(typ string) JOSEOption {
+	return func(o *joseOptions) { o.typ = typ }
+}
+
+// WithAllowedAlgorithms restricts the JWS algorithms accepted by verification.
+func (joseUtility) WithAllowedAlgorithms(algorithms ...JWSAlgorithm) JOSEOption {
+	return func(o *joseOptions) { o.algorithms = algorithms }
+}
+
+// CryptographicAlgorithmOf returns the CryptographicAlgorithm that generates keys for the JWS algorithm.
+func (joseUtility) CryptographicAlgorithmOf(algorithm JWSAlgorithm) (CryptographicAlgorithm, error) {
+	switch algorithm {
+	case JWSRS256, JWSPS256:
+		return CryptoRSA2048, nil
+	case JWSES256:
+		return CryptoECDSA256, nil
+	case JWSES384:
+		return CryptoECDSA384, nil
+	case JWSEdDSA:
+		return CryptoEd25519, nil
+	}
+
+	return "", fmt.Errorf("alg=%s: %w", algorithm, ErrJWSUnsupportedAlgorithm)
+}
+
+// AlgorithmOf returns the default JWS algorithm for the key: RS256 for RSA, ES256 or ES384 for ECDSA, and EdDSA for Ed25519.
+func (joseUtility) AlgorithmOf(key interface{}) (JWSAlgorithm, error) {
+	if priv, ok := key.(interface{ Public() crypto.PublicKey }); ok {
+		key = priv.Public()
+	}
+
+	switch pub := key.(type) {
+	case *rsa.PublicKey:
+		return JWSRS256, nil
+	case *ecdsa.PublicKey:
+		switch pub.Curve.Params().BitSize {
+		case 256: // nolint: gomnd
+			return JWSES256, nil
+		case 384: // nolint: gomnd
+			return JWSES384, nil
+		}
+	case ed25519.PublicKey:
+		return JWSEdDSA, nil
+	}
+
+	return "", fmt.Errorf("%T: %w", key, ErrCryptoUnsupportedKeyType)
+}
+
+// signOptions returns the options of Crypto.Sign for the JWS algorithm, and checks that the key can be used with it.
+func (joseUtility) signOptions(algorithm JWSAlgorithm, key interface{}) ([]CryptoSignOption, error) {
+	if _, err := JOSE.CryptographicAlgorithmOf(algorithm); err != nil {
+		return nil, err
+	}
+
+	keyAlgorithm, err := JOSE.AlgorithmOf(key)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case algorithm == JWSPS256 && keyAlgorithm == JWSRS256:
+		return []CryptoSignOption{Crypto.WithHash(crypto.SHA256), Crypto.WithPSS()}, nil
+	case algorithm != keyAlgorithm:
+		return nil, fmt.Errorf("alg=%s key=%T: %w", algorithm, key, ErrJWSAlgorithmDoesNotMatchKey)
+	case algorithm == JWSRS256:
+		return []CryptoSignOption{Crypto.WithHash(crypto.SHA256)}, nil
+	case algorithm == JWSES256:
+		return []CryptoSignOption{Crypto.WithHash(crypto.SHA256), Crypto.WithRawECDSASignature()}, nil
+	case algorithm == JWSES384:
+		return []CryptoSignOption{Crypto.WithHash(crypto.SHA384), Crypto.WithRawECDSASignature()}, nil
+	}
+
+	return nil, nil
+}
+
+// SignJWS returns the JWS compact serialization of the payload signed with the private key.
+func (joseUtility) SignJWS(privateKey crypto.PrivateKey, payload []byte, opts ...JOSEOption) (string, error) {
+	o := JOSE.newOptions(opts)
+
+	header := JWSHeader{Algorithm: o.algorithm, Type: o.typ, KeyID: o.keyID}
+	if header.Algorithm == "" {
+		algorithm, err := JOSE.AlgorithmOf(privateKey)
+		if err != nil {
+			return "", fmt.Errorf("JOSE.AlgorithmOf: %w", err)
+		}
+
+		header.Algorithm = algorithm
+	}
+
+	signOptions, err := JOSE.signOptions(header.Algorithm, privateKey)
+	if err != nil {
+		return "", err
+	}
+
+	if header.KeyID == "" {
+		jwk, err := JOSE.NewJWK(privateKey)
+		if err != nil {
+			return "", fmt.Errorf("JOSE.NewJWK: %w", err)
+		}
+
+		header.KeyID = jwk.KeyID
+	}
+
+	headerJSON, err := json.Marshal(header)
+	if err != nil {
+		return "", fmt.Errorf("json.Marshal: %w", err)
+	}
+
+	encode := base64.RawURLEncoding.EncodeToString
+	signingInput := encode(headerJSON) + "." + encode(payload)
+
+	signature, err := Crypto.Sign(privateKey, []byte(signingInput), signOptions...)
+	if err != nil {
+		return "", fmt.Errorf("Crypto.Sign: %w", err)
+	}
+
+	return signingInput + "." + encode(signature), nil
+}
+
+// VerifyJWS verifies the JWS compact serialization and returns its header and payload.
+// The key may be crypto.PublicKey, crypto.PrivateKey, *JWK or *JWKS. If *JWKS is passed, the key is looked up by the "kid" header.
+func (joseUtility) VerifyJWS(token string, key interface{}, opts ...JOSEOption) (header *JWSHeader, payload []byte, err error) {
+	o := JOSE.newOptions(opts)
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 { // nolint: gomnd
+		return nil, nil, fmt.Errorf("parts=%d: %w", len(parts), ErrJWSInvalidFormat)
+	}
+
+	decoded := make([][]byte, len(parts))
+	for i, part := range parts {
+		if decoded[i], err = base64.RawURLEncoding.DecodeString(part); err != nil {
+			return nil, nil, fmt.Errorf("parts[%d]: base64.RawURLEncoding.DecodeString: %v: %w", i, err, ErrJWSInvalidFormat)
+		}
+	}
+
+	header = new(JWSHeader)
+	if err := json.Unmarshal(decoded[0], header); err != nil {
+		return nil, nil, fmt.Errorf("header: json.Unmarshal: %v: %w", err, ErrJWSInvalidFormat)
+	}
+
+	if len(header.Critical) > 0 {
+		return nil, nil, fmt.Errorf("crit=%v: %w", header.Critical, ErrJWSInvalidFormat)
+	}
+
+	if len(o.algorithms) > 0 && !Slice.ContainsString(o.algorithms, header.Algorithm) {
+		return nil, nil, fmt.Errorf("alg=%s: not allowed: %w", header.Algorithm, ErrJWSUnsupportedAlgorithm)
+	}
+
+	publicKey, err := JOSE.resolveKey(key, header.KeyID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	signOptions, err := JOSE.signOptions(header.Algorithm, publicKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := Crypto.Verify(publicKey, []byte(parts[0]+"."+parts[1]), decoded[2], signOptions...); err != nil {
+		return nil, nil, fmt.Errorf("Crypto.Verify: %w", err)
+	}
+
+	return header, decoded[1], nil
+}
+
+func (joseUtility) resolveKey(key interface{}, kid string) (crypto.PublicKey, error) {
+	switch k := key.(type) {
+	case *JWKS:
+		jwk, err := k.Lookup(kid)
+		if err != nil {
+			return nil, fmt.Errorf("(*JWKS).Lookup: %w", err)
+		}
+
+		return jwk.PublicKey()
+	case *JWK:
+		return k.PublicKey()
+	}
+
+	return key, nil
+}
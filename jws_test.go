@@ -0,0 +1,124 @@
+// nolint: testpackage
+package nits
+
+import (
+	"crypto/dsa" // nolint: staticcheck
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/nitpickers/nits.go/nitstest"
+)
+
+func Test_joseUtility_SignJWS_VerifyJWS(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name      string
+		algorithm JWSAlgorithm
+	}{
+		{"success(RS256)", JWSRS256},
+		{"success(PS256)", JWSPS256},
+		{"success(ES256)", JWSES256},
+		{"success(ES384)", JWSES384},
+		{"success(EdDSA)", JWSEdDSA},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cryptographicAlgorithm, err := JOSE.CryptographicAlgorithmOf(tt.algorithm)
+			if err != nil {
+				t.Fatalf("JOSE.CryptographicAlgorithmOf: %v", err)
+			}
+			privateKey := Crypto.MustGenerateKey(Crypto.GenerateKey(cryptographicAlgorithm))
+			token, err := JOSE.SignJWS(privateKey, []byte("payload"), JOSE.WithAlgorithm(tt.algorithm))
+			if err != nil {
+				t.Fatalf("JOSE.SignJWS: %v", err)
+			}
+
+			jwks := new(JWKS)
+			jwk, err := jwks.Add(privateKey)
+			if err != nil {
+				t.Fatalf("(*JWKS).Add: %v", err)
+			}
+			header, payload, err := JOSE.VerifyJWS(token, jwks.Public())
+			if err != nil {
+				t.Fatalf("JOSE.VerifyJWS: %v", err)
+			}
+			nitstest.FailIfNotEqual(t, tt.algorithm, header.Algorithm)
+			nitstest.FailIfNotEqual(t, jwk.KeyID, header.KeyID)
+			nitstest.FailIfNotBytesEqual(t, []byte("payload"), payload)
+
+			if _, _, err := JOSE.VerifyJWS(token, jwk); err != nil {
+				t.Errorf("JOSE.VerifyJWS(*JWK): %v", err)
+			}
+			if _, _, err := JOSE.VerifyJWS(token, privateKey, JOSE.WithAllowedAlgorithms("none")); !errors.Is(err, ErrJWSUnsupportedAlgorithm) {
+				t.Errorf("err != ErrJWSUnsupportedAlgorithm: %v", err)
+			}
+			tampered := token[:strings.LastIndex(token, ".")] + "A." + token[strings.LastIndex(token, ".")+1:]
+			if _, _, err := JOSE.VerifyJWS(tampered, privateKey); err == nil {
+				t.Errorf("err == nil")
+			}
+		})
+	}
+}
+
+func Test_joseUtility_SignJWS(t *testing.T) {
+	t.Parallel()
+
+	privateKey := Crypto.MustGenerateKey(Crypto.GenerateKey(CryptoECDSA256))
+	tests := []struct {
+		name       string
+		privateKey interface{}
+		opts       []JOSEOption
+		wantErr    error
+	}{
+		{"failure(ErrCryptoUnsupportedKeyType)", &dsa.PrivateKey{}, nil, ErrCryptoUnsupportedKeyType},
+		{"failure(ErrJWSUnsupportedAlgorithm)", privateKey, []JOSEOption{JOSE.WithAlgorithm("HS256")}, ErrJWSUnsupportedAlgorithm},
+		{"failure(ErrJWSAlgorithmDoesNotMatchKey)", privateKey, []JOSEOption{JOSE.WithAlgorithm(JWSES384)}, ErrJWSAlgorithmDoesNotMatchKey},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if _, err := JOSE.SignJWS(tt.privateKey, []byte("payload"), tt.opts...); !errors.Is(err, tt.wantErr) {
+				t.Errorf("JOSE.SignJWS() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_joseUtility_VerifyJWS(t *testing.T) {
+	t.Parallel()
+
+	privateKey := Crypto.MustGenerateKey(Crypto.GenerateKey(CryptoEd25519))
+	otherKey := Crypto.MustGenerateKey(Crypto.GenerateKey(CryptoECDSA256))
+	token, err := JOSE.SignJWS(privateKey, []byte("payload"), JOSE.WithKeyID("kid"))
+	if err != nil {
+		t.Fatalf("JOSE.SignJWS: %v", err)
+	}
+	tests := []struct {
+		name    string
+		token   string
+		key     interface{}
+		wantErr error
+	}{
+		{"failure(parts)", "a.b", privateKey, ErrJWSInvalidFormat},
+		{"failure(base64)", "!.b.c", privateKey, ErrJWSInvalidFormat},
+		{"failure(header)", "e30K.e30.e30", privateKey, nil},
+		{"failure(json)", "YQ.e30.e30", privateKey, ErrJWSInvalidFormat},
+		{"failure(crit)", "eyJhbGciOiJFZERTQSIsImNyaXQiOlsiZXhwIl19.e30.e30", privateKey, ErrJWSInvalidFormat},
+		{"failure(ErrJOSEKeyNotFound)", token, new(JWKS), ErrJOSEKeyNotFound},
+		{"failure(ErrJWSAlgorithmDoesNotMatchKey)", token, otherKey, ErrJWSAlgorithmDoesNotMatchKey},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, _, err := JOSE.VerifyJWS(tt.token, tt.key)
+			if err == nil || (tt.wantErr != nil && !errors.Is(err, tt.wantErr)) {
+				t.Errorf("JOSE.VerifyJWS() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
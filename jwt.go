@@ -0,0 +1,158 @@
+package nits
+
+import (
+	"crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrJWTHasExpired token has expired.
+	ErrJWTHasExpired = errors.New("token has expired")
+
+	// ErrJWTIsNotYetValid token is not yet valid.
+	ErrJWTIsNotYetValid = errors.New("token is not yet valid")
+
+	// ErrJWTIssuedInTheFuture token is issued in the future.
+	ErrJWTIssuedInTheFuture = errors.New("token is issued in the future")
+
+	// ErrJWTInvalidIssuer token has invalid issuer.
+	ErrJWTInvalidIssuer = errors.New("token has invalid issuer")
+
+	// ErrJWTInvalidAudience token has invalid audience.
+	ErrJWTInvalidAudience = errors.New("token has invalid audience")
+
+	// ErrJWTInvalidClaims token has invalid claims.
+	ErrJWTInvalidClaims = errors.New("token has invalid claims")
+)
+
+// JWTTypeJWT is the "typ" header of JWT.
+const JWTTypeJWT = "JWT"
+
+// JWTClaims is the registered claims of JWT (RFC 7519). It is expected to be embedded in the structure of private claims.
+// The time claims are seconds since the epoch, and zero means absent.
+type JWTClaims struct {
+	Issuer    string      `json:"iss,omitempty"`
+	Subject   string      `json:"sub,omitempty"`
+	Audience  JWTAudience `json:"aud,omitempty"`
+	ExpiresAt int64       `json:"exp,omitempty"`
+	NotBefore int64       `json:"nbf,omitempty"`
+	IssuedAt  int64       `json:"iat,omitempty"`
+	ID        string      `json:"jti,omitempty"`
+}
+
+// JWTAudience is the "aud" claim. It is marshaled as a string if it has only one element.
+type JWTAudience []string
+
+// MarshalJSON implements json.Marshaler.
+func (aud JWTAudience) MarshalJSON() ([]byte, error) {
+	if len(aud) == 1 {
+		return json.Marshal(aud[0]) // nolint: wrapcheck
+	}
+
+	return json.Marshal([]string(aud)) // nolint: wrapcheck
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (aud *JWTAudience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*aud = JWTAudience{single}
+
+		return nil
+	}
+
+	var multiple []string
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return fmt.Errorf("json.Unmarshal: %w", err)
+	}
+
+	*aud = multiple
+
+	return nil
+}
+
+// WithIssuer requires the "iss" claim to be the issuer on verification.
+func (joseUtility) WithIssuer(issuer string) JOSEOption {
+	return func(o *joseOptions) { o.issuer = issuer }
+}
+
+// WithAudience requires the "aud" claim to contain the audience on verification.
+func (joseUtility) WithAudience(audience string) JOSEOption {
+	return func(o *joseOptions) { o.audience = audience }
+}
+
+// WithClockSkew tolerates the difference between the clocks of the issuer and the verifier on verification.
+func (joseUtility) WithClockSkew(clockSkew time.Duration) JOSEOption {
+	return func(o *joseOptions) { o.clockSkew = clockSkew }
+}
+
+// WithClock replaces time.Now on verification.
+func (joseUtility) WithClock(now func() time.Time) JOSEOption {
+	return func(o *joseOptions) { o.now = now }
+}
+
+// IssueJWT returns JWT of the claims signed with the private key.
+// The claims is marshaled by json.Marshal, and is expected to be JWTClaims or a structure that embeds it.
+func (joseUtility) IssueJWT(privateKey crypto.PrivateKey, claims interface{}, opts ...JOSEOption) (string, error) {
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("json.Marshal: %w", err)
+	}
+
+	return JOSE.SignJWS(privateKey, payload, append([]JOSEOption{JOSE.WithType(JWTTypeJWT)}, opts...)...)
+}
+
+// VerifyJWT verifies the signature and the registered claims of JWT, and returns the registered claims.
+// If claims is not nil, the payload is also unmarshaled into it. The key is the same as JOSE.VerifyJWS.
+func (joseUtility) VerifyJWT(token string, key interface{}, claims interface{}, opts ...JOSEOption) (*JWTClaims, error) {
+	_, payload, err := JOSE.VerifyJWS(token, key, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("JOSE.VerifyJWS: %w", err)
+	}
+
+	registered := new(JWTClaims)
+	if err := json.Unmarshal(payload, registered); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal: %v: %w", err, ErrJWTInvalidClaims)
+	}
+
+	if err := JOSE.validateClaims(registered, JOSE.newOptions(opts)); err != nil {
+		return nil, err
+	}
+
+	if claims != nil {
+		if err := json.Unmarshal(payload, claims); err != nil {
+			return nil, fmt.Errorf("json.Unmarshal: %v: %w", err, ErrJWTInvalidClaims)
+		}
+	}
+
+	return registered, nil
+}
+
+func (joseUtility) validateClaims(claims *JWTClaims, o *joseOptions) error {
+	now := o.now()
+
+	if claims.ExpiresAt != 0 && !now.Before(time.Unix(claims.ExpiresAt, 0).Add(o.clockSkew)) {
+		return fmt.Errorf("exp=%d: %w", claims.ExpiresAt, ErrJWTHasExpired)
+	}
+
+	if claims.NotBefore != 0 && now.Add(o.clockSkew).Before(time.Unix(claims.NotBefore, 0)) {
+		return fmt.Errorf("nbf=%d: %w", claims.NotBefore, ErrJWTIsNotYetValid)
+	}
+
+	if claims.IssuedAt != 0 && now.Add(o.clockSkew).Before(time.Unix(claims.IssuedAt, 0)) {
+		return fmt.Errorf("iat=%d: %w", claims.IssuedAt, ErrJWTIssuedInTheFuture)
+	}
+
+	if o.issuer != "" && claims.Issuer != o.issuer {
+		return fmt.Errorf("iss=%s: %w", claims.Issuer, ErrJWTInvalidIssuer)
+	}
+
+	if o.audience != "" && !Slice.ContainsString(claims.Audience, o.audience) {
+		return fmt.Errorf("aud=%v: %w", claims.Audience, ErrJWTInvalidAudience)
+	}
+
+	return nil
+}
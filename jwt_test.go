@@ -0,0 +1,132 @@
+// nolint: testpackage
+package nits
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nitpickers/nits.go/nitstest"
+)
+
+type testJWTClaims struct {
+	JWTClaims
+	Email string `json:"email"`
+}
+
+func Test_joseUtility_IssueJWT_VerifyJWT(t *testing.T) {
+	t.Parallel()
+
+	privateKey, err := X509.ParsePKCSXPrivateKeyPEM([]byte(testPKCS1KeyPEMString))
+	if err != nil {
+		t.Fatalf("X509.ParsePKCSXPrivateKeyPEM: %v", err)
+	}
+	now := time.Unix(1600000000, 0)
+	claims := &testJWTClaims{
+		JWTClaims: JWTClaims{
+			Issuer:    "https://issuer.example.com",
+			Subject:   "subject",
+			Audience:  JWTAudience{"https://audience.example.com"},
+			ExpiresAt: now.Add(time.Hour).Unix(),
+			NotBefore: now.Unix(),
+			IssuedAt:  now.Unix(),
+		},
+		Email: "user@example.com",
+	}
+	token, err := JOSE.IssueJWT(privateKey, claims)
+	if err != nil {
+		t.Fatalf("JOSE.IssueJWT: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		opts    []JOSEOption
+		wantErr error
+	}{
+		{"success()", []JOSEOption{JOSE.WithIssuer(claims.Issuer), JOSE.WithAudience("https://audience.example.com")}, nil},
+		{"success(ClockSkew,exp)", []JOSEOption{JOSE.WithClock(func() time.Time { return now.Add(time.Hour) }), JOSE.WithClockSkew(time.Minute)}, nil},
+		{"success(ClockSkew,nbf)", []JOSEOption{JOSE.WithClock(func() time.Time { return now.Add(-time.Minute) }), JOSE.WithClockSkew(time.Minute)}, nil},
+		{"failure(ErrJWTHasExpired)", []JOSEOption{JOSE.WithClock(func() time.Time { return now.Add(time.Hour) })}, ErrJWTHasExpired},
+		{"failure(ErrJWTIsNotYetValid)", []JOSEOption{JOSE.WithClock(func() time.Time { return now.Add(-time.Second) })}, ErrJWTIsNotYetValid},
+		{"failure(ErrJWTInvalidIssuer)", []JOSEOption{JOSE.WithIssuer("https://other.example.com")}, ErrJWTInvalidIssuer},
+		{"failure(ErrJWTInvalidAudience)", []JOSEOption{JOSE.WithAudience("https://other.example.com")}, ErrJWTInvalidAudience},
+		{"failure(ErrJWSUnsupportedAlgorithm)", []JOSEOption{JOSE.WithAllowedAlgorithms(JWSES256)}, ErrJWSUnsupportedAlgorithm},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			actual := new(testJWTClaims)
+			opts := append([]JOSEOption{JOSE.WithClock(func() time.Time { return now })}, tt.opts...)
+			registered, err := JOSE.VerifyJWT(token, privateKey, actual, opts...)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("JOSE.VerifyJWT() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			nitstest.FailIfNotEqual(t, claims.Subject, registered.Subject)
+			nitstest.FailIfNotEqual(t, claims.Email, actual.Email)
+			nitstest.FailIfNotDeepEqual(t, claims.Audience, actual.Audience)
+		})
+	}
+
+	t.Run("failure(ErrJWTIssuedInTheFuture)", func(t *testing.T) {
+		t.Parallel()
+		token, err := JOSE.IssueJWT(privateKey, JWTClaims{IssuedAt: now.Add(time.Hour).Unix()})
+		if err != nil {
+			t.Fatalf("JOSE.IssueJWT: %v", err)
+		}
+		if _, err := JOSE.VerifyJWT(token, privateKey, nil, JOSE.WithClock(func() time.Time { return now })); !errors.Is(err, ErrJWTIssuedInTheFuture) {
+			t.Errorf("err != ErrJWTIssuedInTheFuture: %v", err)
+		}
+	})
+
+	t.Run("failure(ErrJWTInvalidClaims)", func(t *testing.T) {
+		t.Parallel()
+		token, err := JOSE.SignJWS(privateKey, []byte(`{"exp":"string"}`))
+		if err != nil {
+			t.Fatalf("JOSE.SignJWS: %v", err)
+		}
+		if _, err := JOSE.VerifyJWT(token, privateKey, nil); !errors.Is(err, ErrJWTInvalidClaims) {
+			t.Errorf("err != ErrJWTInvalidClaims: %v", err)
+		}
+	})
+
+	t.Run("failure(json.Marshal)", func(t *testing.T) {
+		t.Parallel()
+		if _, err := JOSE.IssueJWT(privateKey, make(chan int)); err == nil {
+			t.Errorf("err == nil")
+		}
+	})
+}
+
+func TestJWTAudience(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		aud  JWTAudience
+		json string
+	}{
+		{"success(single)", JWTAudience{"a"}, `"a"`},
+		{"success(multiple)", JWTAudience{"a", "b"}, `["a","b"]`},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			nitstest.FailIfNotEqual(t, tt.json, string(JSON.MustMarshal(tt.aud)))
+			var actual JWTAudience
+			JSON.MustUnmarshal([]byte(tt.json), &actual)
+			nitstest.FailIfNotDeepEqual(t, tt.aud, actual)
+		})
+	}
+
+	t.Run("failure()", func(t *testing.T) {
+		t.Parallel()
+		var actual JWTAudience
+		if err := actual.UnmarshalJSON([]byte(`1`)); err == nil {
+			t.Errorf("err == nil")
+		}
+	})
+}
@@ -0,0 +1,420 @@
+package nits
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+)
+
+var (
+	// ErrPasswordMismatch password does not match.
+	ErrPasswordMismatch = errors.New("password does not match")
+
+	// ErrPasswordInvalidHashFormat invalid password hash format.
+	ErrPasswordInvalidHashFormat = errors.New("invalid password hash format")
+
+	// ErrPasswordNoSuchAlgorithm no such password hashing algorithm.
+	ErrPasswordNoSuchAlgorithm = errors.New("no such password hashing algorithm")
+)
+
+// passwordUtility is an empty structure that is prepared only for creating methods.
+type passwordUtility struct{}
+
+// Password is an entity that allows the methods of PasswordUtility to be executed from outside the package without initializing PasswordUtility.
+// nolint: gochecknoglobals
+var Password passwordUtility
+
+// PasswordAlgorithm is an alias of string. It is the identifier of the PHC string format.
+type PasswordAlgorithm = string
+
+const (
+	// PasswordPBKDF2SHA256 PBKDF2 with HMAC-SHA256.
+	PasswordPBKDF2SHA256 PasswordAlgorithm = "pbkdf2-sha256"
+	// PasswordScrypt scrypt.
+	PasswordScrypt PasswordAlgorithm = "scrypt"
+	// PasswordArgon2id Argon2id.
+	PasswordArgon2id PasswordAlgorithm = "argon2id"
+)
+
+// PasswordPolicy is the algorithm and the parameters used for hashing passwords.
+// Hashes with another algorithm or with parameters below the policy are reported as needing rehash.
+type PasswordPolicy struct {
+	Algorithm PasswordAlgorithm
+
+	// PBKDF2Iterations is the iteration count of PasswordPBKDF2SHA256.
+	PBKDF2Iterations int
+
+	// ScryptLogN is log2 of the CPU/memory cost N of PasswordScrypt.
+	ScryptLogN int
+	// ScryptR is the block size of PasswordScrypt.
+	ScryptR int
+	// ScryptP is the parallelization of PasswordScrypt.
+	ScryptP int
+
+	// Argon2Time is the number of passes of PasswordArgon2id.
+	Argon2Time uint32
+	// Argon2Memory is the memory size in KiB of PasswordArgon2id.
+	Argon2Memory uint32
+	// Argon2Threads is the parallelism of PasswordArgon2id.
+	Argon2Threads uint8
+
+	// SaltLength is the length of the random salt in bytes.
+	SaltLength int
+	// KeyLength is the length of the derived key in bytes.
+	KeyLength int
+}
+
+// DefaultPolicy returns PasswordPolicy with Argon2id and the parameters recommended by OWASP.
+// The parameters of the other algorithms are also set, so that only Algorithm needs to be changed to switch.
+func (passwordUtility) DefaultPolicy() PasswordPolicy {
+	return PasswordPolicy{
+		Algorithm:        PasswordArgon2id,
+		PBKDF2Iterations: 600000, // nolint: gomnd
+		ScryptLogN:       17,     // nolint: gomnd
+		ScryptR:          8,      // nolint: gomnd
+		ScryptP:          1,
+		Argon2Time:       2,         // nolint: gomnd
+		Argon2Memory:     19 * 1024, // nolint: gomnd
+		Argon2Threads:    1,
+		SaltLength:       16, // nolint: gomnd
+		KeyLength:        32, // nolint: gomnd
+	}
+}
+
+// Hash hashes the password with Password.DefaultPolicy.
+func (passwordUtility) Hash(password string) (phc string, err error) {
+	return Password.DefaultPolicy().Hash(password)
+}
+
+// Verify verifies the password against the PHC string with Password.DefaultPolicy.
+func (passwordUtility) Verify(password, phc string) (needsRehash bool, err error) {
+	return Password.DefaultPolicy().Verify(password, phc)
+}
+
+// Hash hashes the password with a random salt, and returns it as a PHC string such as:
+//
+//	$pbkdf2-sha256$i=600000$<salt>$<hash>
+//	$scrypt$ln=17,r=8,p=1$<salt>$<hash>
+//	$argon2id$v=19$m=19456,t=2,p=1$<salt>$<hash>
+//
+// The salt and the hash are base64-encoded without padding. The salt must be at least 8 bytes, and the hash at most 64 bytes.
+func (p PasswordPolicy) Hash(password string) (phc string, err error) {
+	if p.SaltLength < passwordMinSaltLength {
+		return "", fmt.Errorf("salt length=%d: must be at least %d: %w", p.SaltLength, passwordMinSaltLength, ErrPasswordInvalidHashFormat)
+	}
+
+	if p.KeyLength < 1 || p.KeyLength > passwordMaxKeyLength {
+		return "", fmt.Errorf("key length=%d: must be 1-%d: %w", p.KeyLength, passwordMaxKeyLength, ErrPasswordInvalidHashFormat)
+	}
+
+	salt := make([]byte, p.SaltLength)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return "", fmt.Errorf("rand.Reader: %w", err)
+	}
+
+	h := &passwordHash{algorithm: p.Algorithm, salt: salt}
+
+	switch p.Algorithm {
+	case PasswordPBKDF2SHA256:
+		h.params = []passwordParam{{"i", p.PBKDF2Iterations}}
+	case PasswordScrypt:
+		h.params = []passwordParam{{"ln", p.ScryptLogN}, {"r", p.ScryptR}, {"p", p.ScryptP}}
+	case PasswordArgon2id:
+		h.version = argon2.Version
+		h.params = []passwordParam{{"m", int(p.Argon2Memory)}, {"t", int(p.Argon2Time)}, {"p", int(p.Argon2Threads)}}
+	default:
+		return "", fmt.Errorf("algorithm=%s: %w", p.Algorithm, ErrPasswordNoSuchAlgorithm)
+	}
+
+	for _, param := range h.params {
+		if err := Password.checkParam(h.algorithm, param.name, param.value, nil); err != nil {
+			return "", err
+		}
+	}
+
+	if h.key, err = h.derive(password, p.KeyLength); err != nil {
+		return "", err
+	}
+
+	return h.String(), nil
+}
+
+// Verify verifies the password against the PHC string in constant time, and reports whether the PHC string needs rehash under the policy.
+// If the password does not match, it returns an error wrapping ErrPasswordMismatch.
+// PHC strings with parameters more than 4 times as costly as the policy, or as Password.DefaultPolicy if it is greater,
+// are rejected with ErrPasswordInvalidHashFormat before deriving the key.
+func (p PasswordPolicy) Verify(password, phc string) (needsRehash bool, err error) {
+	h, err := p.parse(phc)
+	if err != nil {
+		return false, err
+	}
+
+	key, err := h.derive(password, len(h.key))
+	if err != nil {
+		return false, err
+	}
+
+	if subtle.ConstantTimeCompare(key, h.key) != 1 {
+		return false, ErrPasswordMismatch
+	}
+
+	return p.needsRehash(h), nil
+}
+
+// NeedsRehash reports whether the PHC string uses another algorithm than the policy, or parameters below it.
+func (p PasswordPolicy) NeedsRehash(phc string) (bool, error) {
+	h, err := p.parse(phc)
+	if err != nil {
+		return false, err
+	}
+
+	return p.needsRehash(h), nil
+}
+
+func (p PasswordPolicy) needsRehash(h *passwordHash) bool {
+	if h.algorithm != p.Algorithm || len(h.salt) < p.SaltLength || len(h.key) < p.KeyLength {
+		return true
+	}
+
+	switch h.algorithm {
+	case PasswordPBKDF2SHA256:
+		return h.param("i") < p.PBKDF2Iterations
+	case PasswordScrypt:
+		return h.param("ln") < p.ScryptLogN || h.param("r") < p.ScryptR || h.param("p") < p.ScryptP
+	case PasswordArgon2id:
+		return h.version < argon2.Version || h.param("m") < int(p.Argon2Memory) || h.param("t") < int(p.Argon2Time) || h.param("p") < int(p.Argon2Threads)
+	}
+
+	return true
+}
+
+type passwordParam struct {
+	name  string
+	value int
+}
+
+type passwordHash struct {
+	algorithm PasswordAlgorithm
+	version   int
+	params    []passwordParam
+	salt      []byte
+	key       []byte
+}
+
+func (h *passwordHash) param(name string) int {
+	for _, param := range h.params {
+		if param.name == name {
+			return param.value
+		}
+	}
+
+	return 0
+}
+
+func (h *passwordHash) derive(password string, keyLength int) ([]byte, error) {
+	switch h.algorithm {
+	case PasswordPBKDF2SHA256:
+		return pbkdf2.Key([]byte(password), h.salt, h.param("i"), keyLength, sha256.New), nil
+	case PasswordScrypt:
+		key, err := scrypt.Key([]byte(password), h.salt, 1<<h.param("ln"), h.param("r"), h.param("p"), keyLength)
+		if err != nil {
+			return nil, fmt.Errorf("scrypt.Key: %w", err)
+		}
+
+		return key, nil
+	case PasswordArgon2id:
+		return argon2.IDKey([]byte(password), h.salt, uint32(h.param("t")), uint32(h.param("m")), uint8(h.param("p")), uint32(keyLength)), nil
+	}
+
+	return nil, fmt.Errorf("algorithm=%s: %w", h.algorithm, ErrPasswordNoSuchAlgorithm)
+}
+
+func (h *passwordHash) String() string {
+	params := make([]string, 0, len(h.params))
+	for _, param := range h.params {
+		params = append(params, param.name+"="+strconv.Itoa(param.value))
+	}
+
+	fields := []string{"", h.algorithm}
+	if h.version != 0 {
+		fields = append(fields, "v="+strconv.Itoa(h.version))
+	}
+
+	encode := base64.RawStdEncoding.EncodeToString
+
+	return strings.Join(append(fields, strings.Join(params, ","), encode(h.salt), encode(h.key)), "$")
+}
+
+const (
+	// passwordMaxCostFactorLog2 is log2 of how many times the cost of the policy, or of Password.DefaultPolicy if it is greater,
+	// a parsed hash may take at most.
+	passwordMaxCostFactorLog2 = 2
+	passwordMaxCostFactor     = 1 << passwordMaxCostFactorLog2
+
+	// passwordMinSaltLength and passwordMaxKeyLength bound the salt and the hash of a parsed hash,
+	// since the length of the hash is that of the key to derive.
+	passwordMinSaltLength = 8
+	passwordMaxKeyLength  = 64
+)
+
+// nolint: gochecknoglobals
+var passwordParamNames = map[PasswordAlgorithm][]string{
+	PasswordPBKDF2SHA256: {"i"},
+	PasswordScrypt:       {"ln", "r", "p"},
+	PasswordArgon2id:     {"m", "t", "p"},
+}
+
+// paramLimits returns the upper limits of the parameters accepted by parse, so that a crafted or corrupted PHC string
+// cannot exhaust resources. Each limit is passwordMaxCostFactor times the policy or Password.DefaultPolicy, whichever is greater,
+// and the parameters that multiply each other are also limited together by checkCost.
+// The policy itself is not limited, so that Hash accepts any policy stronger than the default.
+func (p PasswordPolicy) paramLimits(algorithm PasswordAlgorithm) map[string]int {
+	d := Password.DefaultPolicy()
+	scale := func(a, b int) int { return passwordMaxCostFactor * max(a, b) }
+
+	switch algorithm {
+	case PasswordPBKDF2SHA256:
+		return map[string]int{"i": scale(p.PBKDF2Iterations, d.PBKDF2Iterations)}
+	case PasswordScrypt:
+		return map[string]int{
+			"ln": max(p.ScryptLogN, d.ScryptLogN) + passwordMaxCostFactorLog2,
+			"r":  scale(p.ScryptR, d.ScryptR),
+			"p":  scale(p.ScryptP, d.ScryptP),
+		}
+	case PasswordArgon2id:
+		return map[string]int{
+			"m": scale(int(p.Argon2Memory), int(d.Argon2Memory)),
+			"t": scale(int(p.Argon2Time), int(d.Argon2Time)),
+			"p": scale(int(p.Argon2Threads), int(d.Argon2Threads)),
+		}
+	}
+
+	return nil
+}
+
+func (p PasswordPolicy) parse(phc string) (*passwordHash, error) {
+	fields := strings.Split(phc, "$")
+	if len(fields) < 5 || fields[0] != "" { // nolint: gomnd
+		return nil, fmt.Errorf("fields=%d: %w", len(fields), ErrPasswordInvalidHashFormat)
+	}
+
+	h := &passwordHash{algorithm: fields[1]}
+
+	limits := p.paramLimits(h.algorithm)
+	if limits == nil {
+		return nil, fmt.Errorf("algorithm=%s: %w", h.algorithm, ErrPasswordNoSuchAlgorithm)
+	}
+
+	rest := fields[2:]
+	if strings.HasPrefix(rest[0], "v=") {
+		version, err := strconv.Atoi(strings.TrimPrefix(rest[0], "v="))
+		if err != nil {
+			return nil, fmt.Errorf("version: strconv.Atoi: %v: %w", err, ErrPasswordInvalidHashFormat)
+		}
+
+		h.version, rest = version, rest[1:]
+	}
+
+	if h.algorithm == PasswordArgon2id && h.version != argon2.Version {
+		return nil, fmt.Errorf("version=%d: %w", h.version, ErrPasswordInvalidHashFormat)
+	}
+
+	if len(rest) != 3 { // nolint: gomnd
+		return nil, fmt.Errorf("fields=%d: %w", len(fields), ErrPasswordInvalidHashFormat)
+	}
+
+	for _, param := range strings.Split(rest[0], ",") {
+		name, value, _ := strings.Cut(param, "=")
+
+		v, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("param=%s: strconv.Atoi: %v: %w", param, err, ErrPasswordInvalidHashFormat)
+		}
+
+		if err := Password.checkParam(h.algorithm, name, v, limits); err != nil {
+			return nil, err
+		}
+
+		h.params = append(h.params, passwordParam{name, v})
+	}
+
+	for name := range limits {
+		if h.param(name) == 0 {
+			return nil, fmt.Errorf("param=%s: missing: %w", name, ErrPasswordInvalidHashFormat)
+		}
+	}
+
+	if err := p.checkCost(h); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if h.salt, err = base64.RawStdEncoding.DecodeString(rest[1]); err != nil {
+		return nil, fmt.Errorf("salt: base64.RawStdEncoding.DecodeString: %v: %w", err, ErrPasswordInvalidHashFormat)
+	}
+
+	if len(h.salt) < passwordMinSaltLength {
+		return nil, fmt.Errorf("salt length=%d: must be at least %d: %w", len(h.salt), passwordMinSaltLength, ErrPasswordInvalidHashFormat)
+	}
+
+	if h.key, err = base64.RawStdEncoding.DecodeString(rest[2]); err != nil {
+		return nil, fmt.Errorf("hash: base64.RawStdEncoding.DecodeString: %v: %w", err, ErrPasswordInvalidHashFormat)
+	}
+
+	if len(h.key) < 1 || len(h.key) > passwordMaxKeyLength {
+		return nil, fmt.Errorf("hash length=%d: must be 1-%d: %w", len(h.key), passwordMaxKeyLength, ErrPasswordInvalidHashFormat)
+	}
+
+	return h, nil
+}
+
+// checkParam checks that the parameter is known and positive, and within the limit if limits is not nil.
+func (passwordUtility) checkParam(algorithm PasswordAlgorithm, name string, value int, limits map[string]int) error {
+	if !slices.Contains(passwordParamNames[algorithm], name) {
+		return fmt.Errorf("param=%s: unknown: %w", name, ErrPasswordInvalidHashFormat)
+	}
+
+	if value < 1 {
+		return fmt.Errorf("param=%s=%d: must be positive: %w", name, value, ErrPasswordInvalidHashFormat)
+	}
+
+	if limit, ok := limits[name]; ok && value > limit {
+		return fmt.Errorf("param=%s=%d: must be at most %d: %w", name, value, limit, ErrPasswordInvalidHashFormat)
+	}
+
+	return nil
+}
+
+// checkCost checks that the memory and CPU cost of the hash, to which the parameters contribute as a product,
+// is within passwordMaxCostFactor times the policy or Password.DefaultPolicy, whichever is greater.
+func (p PasswordPolicy) checkCost(h *passwordHash) error {
+	d := Password.DefaultPolicy()
+
+	var cost, limit int
+
+	switch h.algorithm {
+	case PasswordScrypt:
+		cost, limit = h.param("r")*h.param("p")<<h.param("ln"), max(p.ScryptR*p.ScryptP<<p.ScryptLogN, d.ScryptR*d.ScryptP<<d.ScryptLogN)
+	case PasswordArgon2id:
+		cost, limit = h.param("m")*h.param("t"), max(int(p.Argon2Memory)*int(p.Argon2Time), int(d.Argon2Memory)*int(d.Argon2Time))
+	default:
+		return nil
+	}
+
+	if cost > passwordMaxCostFactor*limit {
+		return fmt.Errorf("cost=%d: must be at most %d: %w", cost, passwordMaxCostFactor*limit, ErrPasswordInvalidHashFormat)
+	}
+
+	return nil
+}
@@ -0,0 +1,228 @@
+// nolint: testpackage
+package nits
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/nitpickers/nits.go/nitstest"
+)
+
+func testPasswordPolicy(algorithm PasswordAlgorithm) PasswordPolicy {
+	policy := Password.DefaultPolicy()
+	policy.Algorithm = algorithm
+	policy.PBKDF2Iterations = 1000
+	policy.ScryptLogN = 10
+	policy.Argon2Memory = 1024
+
+	return policy
+}
+
+func TestPasswordPolicy_Hash_Verify(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		policy PasswordPolicy
+		prefix string
+	}{
+		{"success(PasswordPBKDF2SHA256)", testPasswordPolicy(PasswordPBKDF2SHA256), "$pbkdf2-sha256$i=1000$"},
+		{"success(PasswordScrypt)", testPasswordPolicy(PasswordScrypt), "$scrypt$ln=10,r=8,p=1$"},
+		{"success(PasswordArgon2id)", testPasswordPolicy(PasswordArgon2id), "$argon2id$v=19$m=1024,t=2,p=1$"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			phc, err := tt.policy.Hash("password")
+			if err != nil {
+				t.Fatalf("(PasswordPolicy).Hash: %v", err)
+			}
+			if !strings.HasPrefix(phc, tt.prefix) {
+				t.Errorf("phc=%s: want prefix %s", phc, tt.prefix)
+			}
+
+			needsRehash, err := tt.policy.Verify("password", phc)
+			if err != nil {
+				t.Fatalf("(PasswordPolicy).Verify: %v", err)
+			}
+			nitstest.FailIfNotEqual(t, false, needsRehash)
+
+			if _, err := tt.policy.Verify("wrong", phc); !errors.Is(err, ErrPasswordMismatch) {
+				t.Errorf("err != ErrPasswordMismatch: %v", err)
+			}
+
+			needsRehash, err = Password.Verify("password", phc)
+			if err != nil {
+				t.Fatalf("Password.Verify: %v", err)
+			}
+			nitstest.FailIfNotEqual(t, true, needsRehash)
+		})
+	}
+}
+
+func TestPasswordPolicy_NeedsRehash(t *testing.T) {
+	t.Parallel()
+
+	policy := testPasswordPolicy(PasswordPBKDF2SHA256)
+	phc, err := policy.Hash("password")
+	if err != nil {
+		t.Fatalf("(PasswordPolicy).Hash: %v", err)
+	}
+	stronger := policy
+	stronger.PBKDF2Iterations++
+	longer := policy
+	longer.KeyLength++
+	other := testPasswordPolicy(PasswordScrypt)
+
+	tests := []struct {
+		name   string
+		policy PasswordPolicy
+		want   bool
+	}{
+		{"success(same)", policy, false},
+		{"success(weaker)", testPasswordPolicy(PasswordPBKDF2SHA256), false},
+		{"success(stronger)", stronger, true},
+		{"success(longer)", longer, true},
+		{"success(other)", other, true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			actual, err := tt.policy.NeedsRehash(phc)
+			if err != nil {
+				t.Fatalf("(PasswordPolicy).NeedsRehash: %v", err)
+			}
+			nitstest.FailIfNotEqual(t, tt.want, actual)
+		})
+	}
+
+	t.Run("failure()", func(t *testing.T) {
+		t.Parallel()
+		if _, err := policy.NeedsRehash(""); !errors.Is(err, ErrPasswordInvalidHashFormat) {
+			t.Errorf("err != ErrPasswordInvalidHashFormat: %v", err)
+		}
+	})
+}
+
+func TestPasswordPolicy_Hash(t *testing.T) {
+	t.Parallel()
+
+	noSuchAlgorithm := testPasswordPolicy("bcrypt")
+	zeroThreads := testPasswordPolicy(PasswordArgon2id)
+	zeroThreads.Argon2Threads = 0
+	zeroKeyLength := testPasswordPolicy(PasswordPBKDF2SHA256)
+	zeroKeyLength.KeyLength = 0
+	longKeyLength := testPasswordPolicy(PasswordPBKDF2SHA256)
+	longKeyLength.KeyLength = 65
+	shortSaltLength := testPasswordPolicy(PasswordPBKDF2SHA256)
+	shortSaltLength.SaltLength = 7
+	negativeSaltLength := testPasswordPolicy(PasswordPBKDF2SHA256)
+	negativeSaltLength.SaltLength = -1
+
+	tests := []struct {
+		name    string
+		policy  PasswordPolicy
+		wantErr error
+	}{
+		{"failure(ErrPasswordNoSuchAlgorithm)", noSuchAlgorithm, ErrPasswordNoSuchAlgorithm},
+		{"failure(Argon2Threads)", zeroThreads, ErrPasswordInvalidHashFormat},
+		{"failure(KeyLength)", zeroKeyLength, ErrPasswordInvalidHashFormat},
+		{"failure(KeyLength,long)", longKeyLength, ErrPasswordInvalidHashFormat},
+		{"failure(SaltLength)", shortSaltLength, ErrPasswordInvalidHashFormat},
+		{"failure(SaltLength,negative)", negativeSaltLength, ErrPasswordInvalidHashFormat},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if _, err := tt.policy.Hash("password"); !errors.Is(err, tt.wantErr) {
+				t.Errorf("(PasswordPolicy).Hash() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPasswordPolicy_parse(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		phc     string
+		wantErr error
+	}{
+		{"failure(fields)", "$pbkdf2-sha256$i=1000$c2FsdHNhbHQ", ErrPasswordInvalidHashFormat},
+		{"failure(prefix)", "pbkdf2-sha256$i=1000$c2FsdHNhbHQ$aGFzaA$", ErrPasswordInvalidHashFormat},
+		{"failure(algorithm)", "$bcrypt$i=1000$c2FsdHNhbHQ$aGFzaA", ErrPasswordNoSuchAlgorithm},
+		{"failure(version)", "$argon2id$v=x$m=1024,t=2,p=1$c2FsdHNhbHQ$aGFzaA", ErrPasswordInvalidHashFormat},
+		{"failure(version,unsupported)", "$argon2id$v=16$m=1024,t=2,p=1$c2FsdHNhbHQ$aGFzaA", ErrPasswordInvalidHashFormat},
+		{"failure(argon2id,NoVersion)", "$argon2id$m=1024,t=2,p=1$c2FsdHNhbHQ$aGFzaA", ErrPasswordInvalidHashFormat},
+		{"failure(fields,extra)", "$pbkdf2-sha256$i=1000$c2FsdHNhbHQ$aGFzaA$", ErrPasswordInvalidHashFormat},
+		{"failure(param,unknown)", "$pbkdf2-sha256$x=1000$c2FsdHNhbHQ$aGFzaA", ErrPasswordInvalidHashFormat},
+		{"failure(param,atoi)", "$pbkdf2-sha256$i=x$c2FsdHNhbHQ$aGFzaA", ErrPasswordInvalidHashFormat},
+		{"failure(param,limit)", "$pbkdf2-sha256$i=99999999999$c2FsdHNhbHQ$aGFzaA", ErrPasswordInvalidHashFormat},
+		{"failure(param,limit,pbkdf2)", "$pbkdf2-sha256$i=10000000$c2FsdHNhbHQ$aGFzaA", ErrPasswordInvalidHashFormat},
+		{"failure(param,limit,scrypt)", "$scrypt$ln=24,r=8,p=1$c2FsdHNhbHQ$aGFzaA", ErrPasswordInvalidHashFormat},
+		{"failure(param,limit,argon2id)", "$argon2id$v=19$m=1048576,t=2,p=1$c2FsdHNhbHQ$aGFzaA", ErrPasswordInvalidHashFormat},
+		{"failure(cost,scrypt)", "$scrypt$ln=19,r=32,p=4$c2FsdHNhbHQ$aGFzaA", ErrPasswordInvalidHashFormat},
+		{"failure(cost,argon2id)", "$argon2id$v=19$m=77824,t=8,p=1$c2FsdHNhbHQ$aGFzaA", ErrPasswordInvalidHashFormat},
+		{"failure(param,missing)", "$scrypt$ln=10,r=8$c2FsdHNhbHQ$aGFzaA", ErrPasswordInvalidHashFormat},
+		{"failure(salt)", "$pbkdf2-sha256$i=1000$!$aGFzaA", ErrPasswordInvalidHashFormat},
+		{"failure(hash)", "$pbkdf2-sha256$i=1000$c2FsdHNhbHQ$!", ErrPasswordInvalidHashFormat},
+		{"failure(hash,empty)", "$pbkdf2-sha256$i=1000$c2FsdHNhbHQ$", ErrPasswordInvalidHashFormat},
+		{"failure(hash,long)", "$pbkdf2-sha256$i=1000$c2FsdHNhbHQ$" + strings.Repeat("A", 88), ErrPasswordInvalidHashFormat},
+		{"failure(salt,short)", "$pbkdf2-sha256$i=1000$c2FsdA$aGFzaA", ErrPasswordInvalidHashFormat},
+		{"failure(salt,empty)", "$pbkdf2-sha256$i=1000$$aGFzaA", ErrPasswordInvalidHashFormat},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if _, err := Password.Verify("password", tt.phc); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Password.Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPasswordPolicy_parse_Limits(t *testing.T) {
+	t.Parallel()
+
+	// RFC 9106 4. the second recommended option, which is more costly than 4 times Password.DefaultPolicy.
+	stronger := Password.DefaultPolicy()
+	stronger.Argon2Memory = 64 * 1024
+	stronger.Argon2Time = 3
+	stronger.Argon2Threads = 4
+	phc := "$argon2id$v=19$m=65536,t=3,p=4$c2FsdHNhbHQ$aGFzaA"
+
+	tests := []struct {
+		name    string
+		policy  PasswordPolicy
+		wantErr error
+	}{
+		{"success(stronger)", stronger, nil},
+		{"failure(default)", Password.DefaultPolicy(), ErrPasswordInvalidHashFormat},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if _, err := tt.policy.NeedsRehash(phc); !errors.Is(err, tt.wantErr) {
+				t.Errorf("(PasswordPolicy).NeedsRehash() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("success(Argon2Threads)", func(t *testing.T) {
+		t.Parallel()
+		policy := testPasswordPolicy(PasswordArgon2id)
+		policy.Argon2Threads = 8
+		phc, err := policy.Hash("password")
+		if err != nil {
+			t.Fatalf("(PasswordPolicy).Hash: %v", err)
+		}
+		if _, err := policy.Verify("password", phc); err != nil {
+			t.Errorf("(PasswordPolicy).Verify: %v", err)
+		}
+	})
+}
@@ -0,0 +1,111 @@
+package nits
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+)
+
+// SecretRedacted is the text that Secret prints instead of its value.
+const SecretRedacted = "[REDACTED]"
+
+// Secret holds a sensitive value such as a password or a private key, and prints SecretRedacted instead of it
+// via fmt, encoding/json and encoding.TextMarshaler, so that it does not end up in logs by accident.
+// The copies of a Secret share the same buffer, so Destroy affects all of them.
+// The zero value is an empty Secret.
+type Secret struct {
+	buffer *secretBuffer
+}
+
+type secretBuffer struct {
+	mu    sync.RWMutex
+	value []byte
+}
+
+// NewSecret returns Secret that holds a copy of the value. The caller may zero the value passed after that.
+func (cryptoUtility) NewSecret(value []byte) Secret {
+	return Secret{buffer: &secretBuffer{value: append([]byte{}, value...)}}
+}
+
+// NewSecretString returns Secret that holds a copy of the value.
+// Note that the string passed itself cannot be zeroed, because strings are immutable in Go.
+func (cryptoUtility) NewSecretString(value string) Secret {
+	return Secret{buffer: &secretBuffer{value: []byte(value)}}
+}
+
+// Reveal returns the raw value. The returned slice shares the buffer, so it is zeroed by Destroy and must not be modified.
+// It returns nil after Destroy.
+func (s Secret) Reveal() []byte {
+	if s.buffer == nil {
+		return nil
+	}
+
+	s.buffer.mu.RLock()
+	defer s.buffer.mu.RUnlock()
+
+	return s.buffer.value
+}
+
+// RevealString returns the raw value as string. The returned string is a copy, so it is not zeroed by Destroy.
+func (s Secret) RevealString() string {
+	return string(s.Reveal())
+}
+
+// Destroy zeroes the buffer and releases it. Reveal returns nil after that.
+func (s Secret) Destroy() {
+	if s.buffer == nil {
+		return
+	}
+
+	s.buffer.mu.Lock()
+	defer s.buffer.mu.Unlock()
+
+	for i := range s.buffer.value {
+		s.buffer.value[i] = 0
+	}
+
+	s.buffer.value = nil
+}
+
+// IsEmpty reports whether the value is empty or destroyed.
+func (s Secret) IsEmpty() bool {
+	return len(s.Reveal()) == 0
+}
+
+// String implements fmt.Stringer. It returns SecretRedacted.
+func (Secret) String() string {
+	return SecretRedacted
+}
+
+// GoString implements fmt.GoStringer. It returns SecretRedacted.
+func (Secret) GoString() string {
+	return SecretRedacted
+}
+
+// Format implements fmt.Formatter. It prints SecretRedacted for any verb.
+func (Secret) Format(f fmt.State, verb rune) {
+	if verb == 'q' {
+		_, _ = f.Write([]byte(strconv.Quote(SecretRedacted)))
+
+		return
+	}
+
+	_, _ = f.Write([]byte(SecretRedacted))
+}
+
+// MarshalJSON implements json.Marshaler. It returns SecretRedacted as a JSON string.
+func (Secret) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(SecretRedacted)), nil
+}
+
+// MarshalText implements encoding.TextMarshaler. It returns SecretRedacted.
+func (Secret) MarshalText() ([]byte, error) {
+	return []byte(SecretRedacted), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, so that Secret can be read from configuration files.
+func (s *Secret) UnmarshalText(text []byte) error {
+	*s = Crypto.NewSecret(text)
+
+	return nil
+}
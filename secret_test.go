@@ -0,0 +1,107 @@
+// nolint: testpackage
+package nits
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestSecret_Format(t *testing.T) {
+	t.Parallel()
+
+	secret := Crypto.NewSecretString("p@ssw0rd")
+
+	t.Run("success(fmt)", func(t *testing.T) {
+		t.Parallel()
+		for _, format := range []string{"%v", "%+v", "%#v", "%s", "%x", "%d", "%10s"} {
+			if actual := fmt.Sprintf(format, secret); actual != SecretRedacted {
+				t.Errorf("format=%s: actual != SecretRedacted: %s", format, actual)
+			}
+		}
+
+		if actual := fmt.Sprintf("%q", secret); actual != `"[REDACTED]"` {
+			t.Errorf("actual != %q: %s", SecretRedacted, actual)
+		}
+
+		config := struct {
+			Password Secret
+			Pointer  *Secret
+		}{secret, &secret}
+
+		if actual := fmt.Sprintf("%+v", config); strings.Contains(actual, "p@ssw0rd") || strings.Contains(actual, "112 64") {
+			t.Errorf("leaked: %s", actual)
+		}
+	})
+
+	t.Run("success(json)", func(t *testing.T) {
+		t.Parallel()
+		data, err := json.Marshal(map[string]Secret{"password": secret})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(data) != `{"password":"[REDACTED]"}` {
+			t.Errorf("unexpected JSON: %s", data)
+		}
+
+		if text, _ := secret.MarshalText(); string(text) != SecretRedacted {
+			t.Errorf("unexpected text: %s", text)
+		}
+	})
+
+	t.Run("success(UnmarshalText)", func(t *testing.T) {
+		t.Parallel()
+		var config struct {
+			Password Secret `json:"password"`
+		}
+
+		if err := json.Unmarshal([]byte(`{"password":"p@ssw0rd"}`), &config); err != nil {
+			t.Fatal(err)
+		}
+
+		if config.Password.RevealString() != "p@ssw0rd" {
+			t.Errorf("unexpected secret: %s", config.Password.RevealString())
+		}
+	})
+}
+
+func TestSecret_Destroy(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success()", func(t *testing.T) {
+		t.Parallel()
+		value := []byte("p@ssw0rd")
+		secret := Crypto.NewSecret(value)
+		copied := secret
+
+		// NOTE: NewSecret copies the value.
+		value[0] = 0
+		revealed := secret.Reveal()
+
+		if string(revealed) != "p@ssw0rd" {
+			t.Errorf("unexpected secret: %s", revealed)
+		}
+
+		copied.Destroy()
+
+		if string(revealed) != string(make([]byte, len("p@ssw0rd"))) {
+			t.Errorf("buffer is not zeroed: %v", revealed)
+		}
+
+		if secret.Reveal() != nil || !secret.IsEmpty() {
+			t.Errorf("secret is not destroyed: %v", secret.Reveal())
+		}
+	})
+
+	t.Run("success(zero value)", func(t *testing.T) {
+		t.Parallel()
+		var secret Secret
+		secret.Destroy()
+
+		if secret.Reveal() != nil || secret.RevealString() != "" || !secret.IsEmpty() {
+			t.Error("zero value is not empty")
+		}
+	})
+}
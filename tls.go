@@ -0,0 +1,236 @@
+package nits
+
+import (
+	"context"
+	"crypto"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const tlsDefaultReloadInterval = 10 * time.Second
+
+// tlsUtility is an empty structure that is prepared only for creating methods.
+type tlsUtility struct{}
+
+// TLS is an entity that allows the methods of tlsUtility to be executed from outside the package without initializing tlsUtility.
+// nolint: gochecknoglobals
+var TLS tlsUtility
+
+// CertificateReloader serves the certificate and private key PEM files, and reloads them when they change on disk,
+// so that renewed certificates are picked up without restarting the process.
+// A new pair is swapped in atomically only if it is valid; otherwise the current pair keeps being served.
+type CertificateReloader struct {
+	certFile string
+	keyFile  string
+	interval time.Duration
+	now      func() time.Time
+	onReload func(leaf *x509.Certificate, err error)
+
+	current atomic.Pointer[tls.Certificate]
+
+	mu    sync.Mutex
+	stats [2]os.FileInfo // guarded by mu
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// CertificateReloaderOption is an option for TLS.NewCertificateReloader.
+type CertificateReloaderOption func(*CertificateReloader)
+
+// WithCertificateReloaderInterval sets the interval of polling the files. The default is 10 seconds.
+func (tlsUtility) WithCertificateReloaderInterval(interval time.Duration) CertificateReloaderOption {
+	return func(r *CertificateReloader) { r.interval = interval }
+}
+
+// WithCertificateReloaderClock replaces time.Now used to check the validity of the certificate.
+func (tlsUtility) WithCertificateReloaderClock(now func() time.Time) CertificateReloaderOption {
+	return func(r *CertificateReloader) { r.now = now }
+}
+
+// WithCertificateReloaderOnReload sets the function called after every reload attempt triggered by a change of the files,
+// with the new leaf certificate or the error that kept the current pair, e.g. for logging.
+// A failed attempt is retried at every poll until it succeeds, and the function is called each time.
+func (tlsUtility) WithCertificateReloaderOnReload(onReload func(leaf *x509.Certificate, err error)) CertificateReloaderOption {
+	return func(r *CertificateReloader) { r.onReload = onReload }
+}
+
+// NewCertificateReloader loads the certificate chain and private key PEM files, and starts polling them.
+// Polling stops when ctx is done or CertificateReloader.Close is called.
+func (tlsUtility) NewCertificateReloader(ctx context.Context, certFile, keyFile string, opts ...CertificateReloaderOption) (*CertificateReloader, error) {
+	r := &CertificateReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		interval: tlsDefaultReloadInterval,
+		now:      time.Now,
+		onReload: func(*x509.Certificate, error) {},
+		done:     make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	r.mu.Lock()
+	r.stats = r.stat()
+	r.mu.Unlock()
+
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+
+	ctx, r.cancel = context.WithCancel(ctx)
+
+	go r.poll(ctx)
+
+	return r, nil
+}
+
+func (r *CertificateReloader) stat() (stats [2]os.FileInfo) {
+	for i, name := range []string{r.certFile, r.keyFile} {
+		stats[i], _ = os.Stat(name)
+	}
+
+	return stats
+}
+
+func (r *CertificateReloader) poll(ctx context.Context) {
+	defer close(r.done)
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		stats, changed := r.changed()
+		if !changed {
+			continue
+		}
+
+		err := r.Reload()
+
+		var leaf *x509.Certificate
+		if err == nil {
+			leaf = r.current.Load().Leaf
+
+			// The stats are recorded only after a successful reload, so that a failed one, e.g. of a key rotated
+			// before its certificate, is retried at the next poll even if the files do not change again.
+			r.mu.Lock()
+			r.stats = stats
+			r.mu.Unlock()
+		}
+
+		r.onReload(leaf, err)
+	}
+}
+
+func (r *CertificateReloader) changed() (stats [2]os.FileInfo, changed bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stats = r.stat()
+
+	for i := range stats {
+		if (stats[i] == nil) != (r.stats[i] == nil) ||
+			(stats[i] != nil && (!stats[i].ModTime().Equal(r.stats[i].ModTime()) || stats[i].Size() != r.stats[i].Size())) {
+			changed = true
+		}
+	}
+
+	return stats, changed
+}
+
+// Reload loads the files now, and swaps in the new pair if it is valid: the private key must match the leaf certificate,
+// and the leaf certificate must be within its validity. Otherwise, it returns the error and keeps the current pair.
+func (r *CertificateReloader) Reload() error {
+	certPEM, err := os.ReadFile(r.certFile)
+	if err != nil {
+		return fmt.Errorf("os.ReadFile: %w", err)
+	}
+
+	keyPEM, err := os.ReadFile(r.keyFile)
+	if err != nil {
+		return fmt.Errorf("os.ReadFile: %w", err)
+	}
+
+	cert, err := TLS.loadCertificate(certPEM, keyPEM, r.now)
+	if err != nil {
+		return err
+	}
+
+	r.current.Store(cert)
+
+	return nil
+}
+
+func (tlsUtility) loadCertificate(certPEM, keyPEM []byte, now func() time.Time) (*tls.Certificate, error) {
+	certs, err := X509.ParseCertificatesPEM(certPEM)
+	if err != nil {
+		return nil, fmt.Errorf("X509.ParseCertificatesPEM: %w", err)
+	}
+
+	privateKey, err := X509.ParsePKCSXPrivateKeyPEM(keyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("X509.ParsePKCSXPrivateKeyPEM: %w", err)
+	}
+
+	leaf := certs[0]
+
+	if signer, ok := privateKey.(crypto.Signer); !ok {
+		return nil, fmt.Errorf("%T: %w", privateKey, ErrCryptoUnsupportedKeyType)
+	} else if publicKey, ok := signer.Public().(interface{ Equal(crypto.PublicKey) bool }); !ok || !publicKey.Equal(leaf.PublicKey) {
+		return nil, fmt.Errorf("subject=%s: %w", leaf.Subject, ErrX509PrivateKeyDoesNotMatch)
+	}
+
+	if _, err := X509.CertificateStatus(leaf, X509.WithCertificateStatusClock(now)); err != nil {
+		return nil, fmt.Errorf("X509.CertificateStatus: %w", err)
+	}
+
+	cert := &tls.Certificate{PrivateKey: privateKey, Leaf: leaf}
+	for _, c := range certs {
+		cert.Certificate = append(cert.Certificate, c.Raw)
+	}
+
+	return cert, nil
+}
+
+// Certificate returns the pair being served.
+func (r *CertificateReloader) Certificate() *tls.Certificate {
+	return r.current.Load()
+}
+
+// GetCertificate implements tls.Config.GetCertificate.
+func (r *CertificateReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return r.current.Load(), nil
+}
+
+// GetClientCertificate implements tls.Config.GetClientCertificate, so that the pair can also be used by TLS clients.
+func (r *CertificateReloader) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	return r.current.Load(), nil
+}
+
+// TLSConfig returns *tls.Config that serves the current pair, which can be set to http.Server.TLSConfig for HTTP.ListenAndServe.
+func (r *CertificateReloader) TLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: r.GetCertificate,
+	}
+}
+
+// Close stops polling the files and waits for it to finish. The current pair keeps being served.
+func (r *CertificateReloader) Close() error {
+	r.cancel()
+	<-r.done
+
+	return nil
+}
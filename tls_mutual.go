@@ -0,0 +1,206 @@
+package nits
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrTLSPeerCertificateIsMissing peer certificate is missing.
+	ErrTLSPeerCertificateIsMissing = errors.New("peer certificate is missing")
+
+	// ErrTLSPeerIsNotAuthorized peer is not authorized.
+	ErrTLSPeerIsNotAuthorized = errors.New("peer is not authorized")
+)
+
+const tlsSPIFFEScheme = "spiffe"
+
+// NewCertPool returns *x509.CertPool of all the certificates in the passed PEM bundle.
+func (tlsUtility) NewCertPool(pemData []byte) (*x509.CertPool, error) {
+	certs, err := X509.ParseCertificatesPEM(pemData)
+	if err != nil {
+		return nil, fmt.Errorf("X509.ParseCertificatesPEM: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	for _, cert := range certs {
+		pool.AddCert(cert)
+	}
+
+	return pool, nil
+}
+
+// X509KeyPair returns *tls.Certificate of the certificate chain and private key PEM data.
+// Unlike tls.X509KeyPair, it also accepts the private keys that X509.ParsePKCSXPrivateKeyPEM accepts, and rejects the leaf certificate out of its validity.
+func (tlsUtility) X509KeyPair(certPEM, keyPEM []byte) (*tls.Certificate, error) {
+	return TLS.loadCertificate(certPEM, keyPEM, time.Now)
+}
+
+// NewMutualServerConfig returns *tls.Config for servers that serve the key pair and require client certificates issued by the CA bundle.
+func (tlsUtility) NewMutualServerConfig(caPEM, certPEM, keyPEM []byte) (*tls.Config, error) {
+	clientCAs, err := TLS.NewCertPool(caPEM)
+	if err != nil {
+		return nil, err
+	}
+
+	cert, err := TLS.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{*cert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCAs,
+	}, nil
+}
+
+// NewMutualClientConfig returns *tls.Config for clients that present the key pair and trust only the servers issued by the CA bundle.
+func (tlsUtility) NewMutualClientConfig(caPEM, certPEM, keyPEM []byte) (*tls.Config, error) {
+	rootCAs, err := TLS.NewCertPool(caPEM)
+	if err != nil {
+		return nil, err
+	}
+
+	cert, err := TLS.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{*cert},
+		RootCAs:      rootCAs,
+	}, nil
+}
+
+// PeerAllowlist is the list of the peers authorized by TLS.AuthorizePeer. A peer is authorized if any of its names matches.
+// An empty allowlist authorizes no peer.
+type PeerAllowlist struct {
+	// CommonNames are the subject common names.
+	CommonNames []string
+	// DNSNames are the DNS names in SANs. "*.example.com" allows any name under example.com.
+	DNSNames []string
+	// EmailAddresses are the email addresses in SANs.
+	EmailAddresses []string
+	// SPIFFEIDs are the SPIFFE IDs, such as "spiffe://example.org/ns/default/sa/app".
+	// An ID that ends with "/" allows any ID under it, such as "spiffe://example.org/" for the whole trust domain.
+	SPIFFEIDs []string
+}
+
+// PeerIdentity is the identity of the verified peer, which TLS.PeerAuthorizationMiddleware puts in the request context.
+type PeerIdentity struct {
+	CommonName     string   `json:"commonName"`
+	DNSNames       []string `json:"dnsNames,omitempty"`
+	EmailAddresses []string `json:"emailAddresses,omitempty"`
+	URIs           []string `json:"uris,omitempty"`
+	SPIFFEID       string   `json:"spiffeID,omitempty"`
+	// Certificate is the leaf certificate of the peer.
+	Certificate *x509.Certificate `json:"-"`
+}
+
+// PeerIdentity returns the identity of the leaf certificate.
+func (tlsUtility) PeerIdentity(cert *x509.Certificate) *PeerIdentity {
+	identity := &PeerIdentity{
+		CommonName:     cert.Subject.CommonName,
+		DNSNames:       cert.DNSNames,
+		EmailAddresses: cert.EmailAddresses,
+		Certificate:    cert,
+	}
+
+	for _, uri := range cert.URIs {
+		identity.URIs = append(identity.URIs, uri.String())
+		if uri.Scheme == tlsSPIFFEScheme && identity.SPIFFEID == "" {
+			identity.SPIFFEID = uri.String()
+		}
+	}
+
+	return identity
+}
+
+// AuthorizePeer returns the identity of the verified peer of the connection if it is in the allowlist.
+// It returns an error that wraps ErrTLSPeerCertificateIsMissing if the peer has not presented a verified certificate,
+// or ErrTLSPeerIsNotAuthorized if the peer is not in the allowlist.
+func (tlsUtility) AuthorizePeer(state *tls.ConnectionState, allowlist PeerAllowlist) (*PeerIdentity, error) {
+	if state == nil || len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return nil, ErrTLSPeerCertificateIsMissing // nolint: wrapcheck
+	}
+
+	identity := TLS.PeerIdentity(state.VerifiedChains[0][0])
+
+	if x509MatchAny(identity.CommonName, allowlist.CommonNames, tlsMatchString) ||
+		tlsMatchAnyOf(identity.DNSNames, allowlist.DNSNames, x509MatchDNSName) ||
+		tlsMatchAnyOf(identity.EmailAddresses, allowlist.EmailAddresses, strings.EqualFold) ||
+		(identity.SPIFFEID != "" && x509MatchAny(identity.SPIFFEID, allowlist.SPIFFEIDs, tlsMatchSPIFFEID)) {
+		return identity, nil
+	}
+
+	return nil, fmt.Errorf("subject=%s: %w", identity.Certificate.Subject, ErrTLSPeerIsNotAuthorized)
+}
+
+func tlsMatchString(value, allowed string) bool {
+	return value != "" && value == allowed
+}
+
+func tlsMatchSPIFFEID(id, allowed string) bool {
+	if strings.HasSuffix(allowed, "/") {
+		return strings.HasPrefix(id, allowed)
+	}
+
+	return id == allowed
+}
+
+func tlsMatchAnyOf(values, allowed []string, match func(string, string) bool) bool {
+	for _, value := range values {
+		if x509MatchAny(value, allowed, match) {
+			return true
+		}
+	}
+
+	return false
+}
+
+type tlsPeerIdentityContextKey struct{}
+
+// ContextWithPeerIdentity returns a copy of ctx that carries the identity.
+func (tlsUtility) ContextWithPeerIdentity(ctx context.Context, identity *PeerIdentity) context.Context {
+	return context.WithValue(ctx, tlsPeerIdentityContextKey{}, identity)
+}
+
+// PeerIdentityFromContext returns the identity put in ctx by TLS.PeerAuthorizationMiddleware.
+func (tlsUtility) PeerIdentityFromContext(ctx context.Context) (*PeerIdentity, bool) {
+	identity, ok := ctx.Value(tlsPeerIdentityContextKey{}).(*PeerIdentity)
+
+	return identity, ok
+}
+
+// PeerAuthorizationMiddleware returns a middleware that authorizes the verified peer of the request by TLS.AuthorizePeer,
+// and puts its identity in the request context, which can be taken by TLS.PeerIdentityFromContext.
+// It responds 401 Unauthorized if the peer has not presented a verified certificate, or 403 Forbidden if the peer is not in the allowlist.
+// It can be combined with other middlewares by HTTP.AddMiddlewares.
+func (tlsUtility) PeerAuthorizationMiddleware(allowlist PeerAllowlist) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			identity, err := TLS.AuthorizePeer(r.TLS, allowlist)
+			if err != nil {
+				status := http.StatusForbidden
+				if errors.Is(err, ErrTLSPeerCertificateIsMissing) {
+					status = http.StatusUnauthorized
+				}
+
+				http.Error(w, http.StatusText(status), status)
+
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(TLS.ContextWithPeerIdentity(r.Context(), identity)))
+		})
+	}
+}
@@ -0,0 +1,192 @@
+// nolint: testpackage
+package nits
+
+import (
+	"crypto"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func testIssueKeyPairPEM(t *testing.T, issue func(crypto.PublicKey, ...X509CertificateOption) (*x509.Certificate, []byte, error), opts ...X509CertificateOption) (*x509.Certificate, []byte, []byte) {
+	t.Helper()
+
+	privateKey, err := Crypto.GenerateKey(CryptoECDSA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cert, certPEM, err := issue(privateKey.(crypto.Signer).Public(), opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyPEM, err := X509.MarshalPKCSXPrivateKeyPEM(privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return cert, certPEM, keyPEM
+}
+
+func TestTLSUtility_PeerAuthorizationMiddleware(t *testing.T) {
+	t.Parallel()
+
+	ca := testNewRootCA(t)
+	caPEM := X509.MarshalCertificatePEM(ca.Certificate())
+
+	_, serverCertPEM, serverKeyPEM := testIssueKeyPairPEM(t, ca.IssueServerCertificate, X509.WithSANs("localhost", "127.0.0.1"))
+	_, clientCertPEM, clientKeyPEM := testIssueKeyPairPEM(t, ca.IssueClientCertificate,
+		X509.WithSubject(pkix.Name{CommonName: "client"}),
+		X509.WithSANs("spiffe://example.org/ns/default/sa/client"),
+	)
+
+	serverConfig, err := TLS.NewMutualServerConfig(caPEM, serverCertPEM, serverKeyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clientConfig, err := TLS.NewMutualClientConfig(caPEM, clientCertPEM, clientKeyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newServer := func(t *testing.T, allowlist PeerAllowlist) *httptest.Server {
+		t.Helper()
+
+		handler := TLS.PeerAuthorizationMiddleware(allowlist)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			identity, ok := TLS.PeerIdentityFromContext(r.Context())
+			if !ok {
+				t.Error("no peer identity")
+
+				return
+			}
+
+			_, _ = io.WriteString(w, identity.SPIFFEID)
+		}))
+
+		server := httptest.NewUnstartedServer(handler)
+		server.TLS = serverConfig
+		server.StartTLS()
+
+		return server
+	}
+
+	get := func(server *httptest.Server, config *tls.Config) (int, string) {
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: config}}
+
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			return 0, err.Error()
+		}
+		defer resp.Body.Close()
+
+		body, _ := io.ReadAll(resp.Body)
+
+		return resp.StatusCode, string(body)
+	}
+
+	t.Run("success(SPIFFEID)", func(t *testing.T) {
+		t.Parallel()
+		server := newServer(t, PeerAllowlist{SPIFFEIDs: []string{"spiffe://example.org/ns/default/"}})
+		defer server.Close()
+
+		if status, body := get(server, clientConfig); status != http.StatusOK || body != "spiffe://example.org/ns/default/sa/client" {
+			t.Errorf("unexpected response: %d %s", status, body)
+		}
+	})
+
+	t.Run("success(CommonName)", func(t *testing.T) {
+		t.Parallel()
+		server := newServer(t, PeerAllowlist{CommonNames: []string{"client"}})
+		defer server.Close()
+
+		if status, body := get(server, clientConfig); status != http.StatusOK {
+			t.Errorf("unexpected response: %d %s", status, body)
+		}
+	})
+
+	t.Run("failure(403)", func(t *testing.T) {
+		t.Parallel()
+		server := newServer(t, PeerAllowlist{SPIFFEIDs: []string{"spiffe://example.org/ns/default/sa/other"}, DNSNames: []string{"*.example.org"}})
+		defer server.Close()
+
+		if status, body := get(server, clientConfig); status != http.StatusForbidden {
+			t.Errorf("unexpected response: %d %s", status, body)
+		}
+	})
+
+	t.Run("failure(NoClientCertificate)", func(t *testing.T) {
+		t.Parallel()
+		server := newServer(t, PeerAllowlist{CommonNames: []string{"client"}})
+		defer server.Close()
+
+		noCertConfig := clientConfig.Clone()
+		noCertConfig.Certificates = nil
+
+		if status, _ := get(server, noCertConfig); status != 0 {
+			t.Errorf("the handshake should fail: %d", status)
+		}
+	})
+}
+
+func TestTLSUtility_AuthorizePeer(t *testing.T) {
+	t.Parallel()
+	ca := testNewRootCA(t)
+	cert, _, _ := testIssueKeyPairPEM(t, ca.IssueClientCertificate, X509.WithSANs("app.example.com", "app@example.com"))
+	state := &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert, ca.Certificate()}}}
+	tests := []struct {
+		name      string
+		state     *tls.ConnectionState
+		allowlist PeerAllowlist
+		wantErr   error
+	}{
+		{"success(DNSNames)", state, PeerAllowlist{DNSNames: []string{"*.example.com"}}, nil},
+		{"success(EmailAddresses)", state, PeerAllowlist{EmailAddresses: []string{"APP@example.com"}}, nil},
+		{"failure(ErrTLSPeerIsNotAuthorized,empty)", state, PeerAllowlist{}, ErrTLSPeerIsNotAuthorized},
+		{"failure(ErrTLSPeerIsNotAuthorized,CommonNames)", state, PeerAllowlist{CommonNames: []string{""}}, ErrTLSPeerIsNotAuthorized},
+		{"failure(ErrTLSPeerIsNotAuthorized,SPIFFEIDs)", state, PeerAllowlist{SPIFFEIDs: []string{"spiffe://example.com/"}}, ErrTLSPeerIsNotAuthorized},
+		{"failure(ErrTLSPeerCertificateIsMissing)", &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}, PeerAllowlist{DNSNames: []string{"*.example.com"}}, ErrTLSPeerCertificateIsMissing},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			identity, err := TLS.AuthorizePeer(tt.state, tt.allowlist)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("TLS.AuthorizePeer() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr == nil && identity.Certificate != cert {
+				t.Errorf("TLS.AuthorizePeer() = %+v, want %v", identity, cert.Subject)
+			}
+		})
+	}
+
+	t.Run("failure(401)", func(t *testing.T) {
+		t.Parallel()
+		rec := httptest.NewRecorder()
+		TLS.PeerAuthorizationMiddleware(PeerAllowlist{})(http.NotFoundHandler()).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("unexpected status: %d", rec.Code)
+		}
+	})
+}
+
+func TestTLSUtility_NewCertPool(t *testing.T) {
+	t.Parallel()
+
+	if _, err := TLS.NewCertPool([]byte("invalid")); !errors.Is(err, ErrX509InvalidPEMFormat) {
+		t.Errorf("err != ErrX509InvalidPEMFormat: %v", err)
+	}
+
+	if _, err := TLS.NewMutualServerConfig([]byte(testCrtPEMString), []byte(testCrtPEMString), []byte("invalid")); !errors.Is(err, ErrX509InvalidPEMFormat) {
+		t.Errorf("err != ErrX509InvalidPEMFormat: %v", err)
+	}
+}
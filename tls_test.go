@@ -0,0 +1,258 @@
+// nolint: testpackage
+package nits
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func testWriteCertificateFiles(t *testing.T, dir string, ca *CA, opts ...X509CertificateOption) (*x509.Certificate, string, string) {
+	t.Helper()
+
+	privateKey, err := Crypto.GenerateKey(CryptoECDSA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cert, certPEM, err := ca.IssueServerCertificate(privateKey.(crypto.Signer).Public(), append([]X509CertificateOption{X509.WithDNSNames("localhost")}, opts...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyPEM, err := X509.MarshalPKCSXPrivateKeyPEM(privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	if err := os.WriteFile(certFile, append(certPEM, X509.MarshalCertificatePEM(ca.Certificate())...), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(keyFile, keyPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return cert, certFile, keyFile
+}
+
+func TestTLSUtility_NewCertificateReloader(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success()", func(t *testing.T) {
+		t.Parallel()
+		ca := testNewRootCA(t)
+		dir := t.TempDir()
+		first, certFile, keyFile := testWriteCertificateFiles(t, dir, ca)
+
+		reloader, err := TLS.NewCertificateReloader(context.Background(), certFile, keyFile, TLS.WithCertificateReloaderInterval(10*time.Millisecond))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer reloader.Close()
+
+		if cert := reloader.Certificate(); !cert.Leaf.Equal(first) || len(cert.Certificate) != 2 {
+			t.Fatalf("unexpected certificate: %v", cert.Leaf.Subject)
+		}
+
+		listener, err := tls.Listen("tcp", "127.0.0.1:0", reloader.TLSConfig())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer listener.Close()
+
+		go func() {
+			for {
+				conn, err := listener.Accept()
+				if err != nil {
+					return
+				}
+
+				_ = conn.(*tls.Conn).Handshake()
+				_ = conn.Close()
+			}
+		}()
+
+		roots := x509.NewCertPool()
+		roots.AddCert(ca.Certificate())
+
+		peer := func() *x509.Certificate {
+			conn, err := tls.Dial("tcp", listener.Addr().String(), &tls.Config{RootCAs: roots, ServerName: "localhost", MinVersion: tls.VersionTLS12})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+
+			return conn.ConnectionState().PeerCertificates[0]
+		}
+
+		if !peer().Equal(first) {
+			t.Error("peer != first")
+		}
+
+		second, _, _ := testWriteCertificateFiles(t, dir, ca)
+
+		// The files may be seen half-written, so wait until the new pair is served.
+		for deadline := time.Now().Add(5 * time.Second); !reloader.Certificate().Leaf.Equal(second); time.Sleep(10 * time.Millisecond) {
+			if time.Now().After(deadline) {
+				t.Fatal("timeout")
+			}
+		}
+
+		if !peer().Equal(second) {
+			t.Error("peer != second")
+		}
+	})
+
+	t.Run("success(KeepCurrentPair)", func(t *testing.T) {
+		t.Parallel()
+		ca := testNewRootCA(t)
+		dir := t.TempDir()
+		first, certFile, keyFile := testWriteCertificateFiles(t, dir, ca)
+
+		reloaded := make(chan error, 1)
+		reloader, err := TLS.NewCertificateReloader(context.Background(), certFile, keyFile,
+			TLS.WithCertificateReloaderInterval(10*time.Millisecond),
+			TLS.WithCertificateReloaderOnReload(func(_ *x509.Certificate, err error) {
+				select {
+				case reloaded <- err:
+				default:
+				}
+			}),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer reloader.Close()
+
+		if err := os.WriteFile(keyFile, []byte("broken"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		select {
+		case err := <-reloaded:
+			if !errors.Is(err, ErrX509InvalidPEMFormat) {
+				t.Errorf("err != ErrX509InvalidPEMFormat: %v", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout")
+		}
+
+		if !reloader.Certificate().Leaf.Equal(first) {
+			t.Error("current pair is not kept")
+		}
+	})
+
+	t.Run("success(Retry)", func(t *testing.T) {
+		t.Parallel()
+		ca := testNewRootCA(t)
+		dir := t.TempDir()
+		_, certFile, keyFile := testWriteCertificateFiles(t, dir, ca)
+
+		// The certificate files are padded to the same size, so that only their mtimes can tell them apart.
+		pad := func(name string) []byte {
+			data, err := os.ReadFile(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			return append(data, bytes.Repeat([]byte("\n"), 4096-len(data))...)
+		}
+
+		if err := os.WriteFile(certFile, pad(certFile), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		reloaded := make(chan error, 1)
+		reloader, err := TLS.NewCertificateReloader(context.Background(), certFile, keyFile,
+			TLS.WithCertificateReloaderInterval(10*time.Millisecond),
+			TLS.WithCertificateReloaderOnReload(func(_ *x509.Certificate, err error) {
+				select {
+				case reloaded <- err:
+				default:
+				}
+			}),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer reloader.Close()
+
+		second, secondCertFile, secondKeyFile := testWriteCertificateFiles(t, t.TempDir(), ca)
+
+		// The key is rotated before the certificate.
+		keyPEM, err := os.ReadFile(secondKeyFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(keyFile, keyPEM, 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		select {
+		case err := <-reloaded:
+			if !errors.Is(err, ErrX509PrivateKeyDoesNotMatch) {
+				t.Fatalf("err != ErrX509PrivateKeyDoesNotMatch: %v", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout")
+		}
+
+		stat, err := os.Stat(certFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(certFile, pad(secondCertFile), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.Chtimes(certFile, stat.ModTime(), stat.ModTime()); err != nil {
+			t.Fatal(err)
+		}
+
+		for deadline := time.Now().Add(5 * time.Second); !reloader.Certificate().Leaf.Equal(second); time.Sleep(10 * time.Millisecond) {
+			if time.Now().After(deadline) {
+				t.Fatal("timeout")
+			}
+		}
+	})
+
+	t.Run("failure(ErrX509PrivateKeyDoesNotMatch)", func(t *testing.T) {
+		t.Parallel()
+		ca := testNewRootCA(t)
+		_, certFile, _ := testWriteCertificateFiles(t, t.TempDir(), ca)
+		_, _, keyFile := testWriteCertificateFiles(t, t.TempDir(), ca)
+
+		if _, err := TLS.NewCertificateReloader(context.Background(), certFile, keyFile); !errors.Is(err, ErrX509PrivateKeyDoesNotMatch) {
+			t.Errorf("err != ErrX509PrivateKeyDoesNotMatch: %v", err)
+		}
+	})
+
+	t.Run("failure(ErrX509CertificateHasExpired)", func(t *testing.T) {
+		t.Parallel()
+		ca := testNewRootCA(t)
+		_, certFile, keyFile := testWriteCertificateFiles(t, t.TempDir(), ca, X509.WithValidity(time.Hour))
+
+		clock := TLS.WithCertificateReloaderClock(func() time.Time { return time.Now().Add(2 * time.Hour) })
+		if _, err := TLS.NewCertificateReloader(context.Background(), certFile, keyFile, clock); !errors.Is(err, ErrX509CertificateHasExpired) {
+			t.Errorf("err != ErrX509CertificateHasExpired: %v", err)
+		}
+	})
+
+	t.Run("failure(NoSuchFile)", func(t *testing.T) {
+		t.Parallel()
+		if _, err := TLS.NewCertificateReloader(context.Background(), "/no/such/file", "/no/such/file"); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("err != os.ErrNotExist: %v", err)
+		}
+	})
+}
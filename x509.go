@@ -29,24 +29,35 @@ type x509Utility struct{}
 // nolint: gochecknoglobals
 var X509 x509Utility
 
-// ParsePKCSXPrivateKeyPEM returns crypto.PrivateKey from the passed PEM data.
+// ParsePKCSXPrivateKeyPEM returns crypto.PrivateKey from the passed PEM data in PKCS #1, PKCS #8 or SEC 1.
+// X509.ParsePEM parses every block of the data by its type.
 func (x509Utility) ParsePKCSXPrivateKeyPEM(pemData []byte) (crypto.PrivateKey, error) {
 	block, _ := pem.Decode(pemData)
 	if block == nil {
 		return nil, ErrX509InvalidPEMFormat // nolint: wrapcheck
 	}
 
-	pkcs1Priv, pkcs1Err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	return x509ParsePrivateKeyDER(block.Bytes)
+}
+
+// x509ParsePrivateKeyDER tries PKCS #1, PKCS #8 and SEC 1 regardless of the PEM block type, since some tools write mislabeled blocks.
+func x509ParsePrivateKeyDER(der []byte) (crypto.PrivateKey, error) {
+	pkcs1Priv, pkcs1Err := x509.ParsePKCS1PrivateKey(der)
 	if pkcs1Err == nil {
 		return pkcs1Priv, nil
 	}
 
-	pkcs8Priv, pkcs8Err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	pkcs8Priv, pkcs8Err := x509.ParsePKCS8PrivateKey(der)
 	if pkcs8Err == nil {
 		return pkcs8Priv, nil
 	}
 
-	return nil, fmt.Errorf("x509.ParsePKCS1PrivateKey=%v, x509.ParsePKCS8PrivateKey=%v: %w", pkcs1Err, pkcs8Err, ErrX509InvalidPEMFormat)
+	sec1Priv, sec1Err := x509.ParseECPrivateKey(der)
+	if sec1Err == nil {
+		return sec1Priv, nil
+	}
+
+	return nil, fmt.Errorf("x509.ParsePKCS1PrivateKey=%v, x509.ParsePKCS8PrivateKey=%v, x509.ParseECPrivateKey=%v: %w", pkcs1Err, pkcs8Err, sec1Err, ErrX509InvalidPEMFormat)
 }
 
 // MarshalPKCSXPrivateKeyPEM returns crypto.PrivateKey from the passed PEM data.
@@ -63,7 +74,7 @@ func (x509Utility) marshalPKCSXPrivateKeyPEM(privateKey crypto.PrivateKey, pem_E
 
 	buf := bytes.NewBuffer(nil)
 	block := pem.Block{
-		Type:  "PRIVATE KEY",
+		Type:  x509PEMTypePrivateKey,
 		Bytes: privateKeyBytes,
 	}
 	if err := pem_Encode(buf, &block); err != nil {
@@ -89,6 +100,7 @@ func (x509Utility) ParseCertificatePEM(pemData []byte) (*x509.Certificate, error
 }
 
 // CheckCertificate returns "not yet valid", "validity starts after how many days", "expired", and "validity expires after how many days" for the certificate passed as argument.
+// X509.CertificateStatus returns the same information with exact durations and an injectable clock.
 func (x509Utility) CheckCertificate(cert *x509.Certificate) (notyet bool, daysToStart int64, expired bool, daysToExpire int64) {
 	return X509.checkCertificate(cert, time.Now())
 }
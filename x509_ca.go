@@ -0,0 +1,513 @@
+package nits
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrX509CertificateIsNotCA certificate is not a CA.
+	ErrX509CertificateIsNotCA = errors.New("certificate is not a CA")
+
+	// ErrX509PrivateKeyDoesNotMatch private key does not match certificate.
+	ErrX509PrivateKeyDoesNotMatch = errors.New("private key does not match certificate")
+
+	// ErrX509PolicyViolation certificate violates CA policy.
+	ErrX509PolicyViolation = errors.New("certificate violates CA policy")
+
+	// ErrX509CertificateNotFound certificate not found.
+	ErrX509CertificateNotFound = errors.New("certificate not found")
+
+	// ErrX509CertificateAlreadyExists certificate already exists.
+	ErrX509CertificateAlreadyExists = errors.New("certificate already exists")
+)
+
+const x509PEMTypeCertificateRequest = "CERTIFICATE REQUEST"
+
+// CAPolicy limits the certificates that CA issues. The zero value allows anything within the validity of the CA certificate.
+type CAPolicy struct {
+	// MaxValidity is the maximum length of the validity. Zero means no limit.
+	MaxValidity time.Duration
+	// AllowedDNSNames are the DNS names allowed in SANs. "*.example.com" allows any name under example.com. Empty means any.
+	AllowedDNSNames []string
+	// AllowedIPRanges are the IP ranges allowed in SANs. Empty means any.
+	AllowedIPRanges []*net.IPNet
+	// AllowedURIPrefixes are the prefixes of the URIs allowed in SANs, such as "spiffe://example.org/". Empty means any.
+	AllowedURIPrefixes []string
+	// AllowedEmailDomains are the domains of the email addresses allowed in SANs, including their subdomains. Empty means any.
+	AllowedEmailDomains []string
+}
+
+// CAStore records the certificates issued by CA.
+type CAStore interface {
+	// Save records the certificate. It returns an error that wraps ErrX509CertificateAlreadyExists if the serial number is already recorded.
+	Save(cert *x509.Certificate) error
+	// Load returns the certificate of the serial number, or an error that wraps ErrX509CertificateNotFound.
+	Load(serialNumber *big.Int) (*x509.Certificate, error)
+	// List returns all the certificates in the order they were saved.
+	List() ([]*x509.Certificate, error)
+}
+
+// MemoryCAStore is CAStore in memory. It is the default store of CA.
+type MemoryCAStore struct {
+	mu    sync.RWMutex
+	certs []*x509.Certificate
+	index map[string]int
+}
+
+// NewMemoryCAStore returns an empty *MemoryCAStore.
+func (x509Utility) NewMemoryCAStore() *MemoryCAStore {
+	return &MemoryCAStore{index: make(map[string]int)}
+}
+
+// Save implements CAStore.
+func (s *MemoryCAStore) Save(cert *x509.Certificate) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	serialNumber := cert.SerialNumber.String()
+	if _, ok := s.index[serialNumber]; ok {
+		return fmt.Errorf("serialNumber=%s: %w", serialNumber, ErrX509CertificateAlreadyExists)
+	}
+
+	s.index[serialNumber] = len(s.certs)
+	s.certs = append(s.certs, cert)
+
+	return nil
+}
+
+// Load implements CAStore.
+func (s *MemoryCAStore) Load(serialNumber *big.Int) (*x509.Certificate, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	i, ok := s.index[serialNumber.String()]
+	if !ok {
+		return nil, fmt.Errorf("serialNumber=%s: %w", serialNumber, ErrX509CertificateNotFound)
+	}
+
+	return s.certs[i], nil
+}
+
+// List implements CAStore.
+func (s *MemoryCAStore) List() ([]*x509.Certificate, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]*x509.Certificate{}, s.certs...), nil
+}
+
+// CA is a minimal certificate authority that issues intermediate CA and leaf certificates with its certificate and private key.
+// Every certificate issued is checked against CAPolicy and the name constraints of the CA certificate, and recorded in CAStore.
+// The common name of a leaf certificate is checked as well as its SANs.
+// Unless NotAfter is set explicitly, the validity defaults to CAPolicy.MaxValidity, and is truncated to NotAfter of the CA certificate.
+type CA struct {
+	cert   *x509.Certificate
+	signer crypto.Signer
+	chain  []*x509.Certificate
+	policy CAPolicy
+	store  CAStore
+	now    func() time.Time
+
+	crlMu     sync.Mutex
+	crlNumber *big.Int // the greatest number of the CRLs created by CreateCRL
+}
+
+// CAOption is an option for X509.NewCA.
+type CAOption func(*CA)
+
+// WithCAPolicy sets the policy of the certificates issued.
+func (x509Utility) WithCAPolicy(policy CAPolicy) CAOption {
+	return func(ca *CA) { ca.policy = policy }
+}
+
+// WithCAStore sets the store of the certificates issued. The default is X509.NewMemoryCAStore.
+func (x509Utility) WithCAStore(store CAStore) CAOption {
+	return func(ca *CA) { ca.store = store }
+}
+
+// WithCAClock replaces time.Now used for NotBefore and the validity check of the CA certificate.
+func (x509Utility) WithCAClock(now func() time.Time) CAOption {
+	return func(ca *CA) { ca.now = now }
+}
+
+// WithCAChain sets the certificates above the CA certificate, from its issuer up to the root, which are returned by CA.Chain.
+func (x509Utility) WithCAChain(chain ...*x509.Certificate) CAOption {
+	return func(ca *CA) { ca.chain = chain }
+}
+
+// NewCA returns *CA of the CA certificate and its private key.
+func (x509Utility) NewCA(cert *x509.Certificate, privateKey crypto.PrivateKey, opts ...CAOption) (*CA, error) {
+	if !cert.IsCA {
+		return nil, fmt.Errorf("subject=%s: %w", cert.Subject, ErrX509CertificateIsNotCA)
+	}
+
+	signer, ok := privateKey.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("%T: %w", privateKey, ErrCryptoUnsupportedKeyType)
+	}
+
+	if publicKey, ok := signer.Public().(interface{ Equal(crypto.PublicKey) bool }); !ok || !publicKey.Equal(cert.PublicKey) {
+		return nil, fmt.Errorf("subject=%s: %w", cert.Subject, ErrX509PrivateKeyDoesNotMatch)
+	}
+
+	ca := &CA{cert: cert, signer: signer, store: X509.NewMemoryCAStore(), now: time.Now}
+	for _, opt := range opts {
+		opt(ca)
+	}
+
+	return ca, nil
+}
+
+// NewCAPEM returns *CA of the PEM data of the CA certificate and its private key.
+func (x509Utility) NewCAPEM(certPEM, privateKeyPEM []byte, opts ...CAOption) (*CA, error) {
+	cert, err := X509.ParseCertificatePEM(certPEM)
+	if err != nil {
+		return nil, fmt.Errorf("X509.ParseCertificatePEM: %w", err)
+	}
+
+	privateKey, err := X509.ParsePKCSXPrivateKeyPEM(privateKeyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("X509.ParsePKCSXPrivateKeyPEM: %w", err)
+	}
+
+	return X509.NewCA(cert, privateKey, opts...)
+}
+
+// Certificate returns the CA certificate.
+func (ca *CA) Certificate() *x509.Certificate {
+	return ca.cert
+}
+
+// Chain returns the CA certificate followed by the certificates set by X509.WithCAChain.
+func (ca *CA) Chain() []*x509.Certificate {
+	return append([]*x509.Certificate{ca.cert}, ca.chain...)
+}
+
+// Store returns the store of the certificates issued.
+func (ca *CA) Store() CAStore {
+	return ca.store
+}
+
+// CreateIntermediate issues an intermediate CA certificate of the private key, and returns *CA of it
+// that shares the policy, the store and the clock. The path length is 0 unless X509.WithCA is passed,
+// and must be less than that of the CA certificate if it is limited.
+func (ca *CA) CreateIntermediate(privateKey crypto.PrivateKey, opts ...X509CertificateOption) (*CA, error) {
+	signer, ok := privateKey.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("%T: %w", privateKey, ErrCryptoUnsupportedKeyType)
+	}
+
+	cert, _, err := ca.issue(signer.Public(), true, append([]X509CertificateOption{X509.WithCA(0)}, opts...))
+	if err != nil {
+		return nil, err
+	}
+
+	return &CA{cert: cert, signer: signer, chain: ca.Chain(), policy: ca.policy, store: ca.store, now: ca.now}, nil
+}
+
+// Issue issues a leaf certificate of the public key in both *x509.Certificate and PEM.
+func (ca *CA) Issue(publicKey crypto.PublicKey, opts ...X509CertificateOption) (cert *x509.Certificate, pemData []byte, err error) {
+	return ca.issue(publicKey, false, opts)
+}
+
+// IssueServerCertificate issues a leaf certificate of the public key for TLS servers.
+func (ca *CA) IssueServerCertificate(publicKey crypto.PublicKey, opts ...X509CertificateOption) (cert *x509.Certificate, pemData []byte, err error) {
+	return ca.issue(publicKey, false, append([]X509CertificateOption{X509.WithExtKeyUsage(x509.ExtKeyUsageServerAuth)}, opts...))
+}
+
+// IssueClientCertificate issues a leaf certificate of the public key for TLS clients.
+func (ca *CA) IssueClientCertificate(publicKey crypto.PublicKey, opts ...X509CertificateOption) (cert *x509.Certificate, pemData []byte, err error) {
+	return ca.issue(publicKey, false, append([]X509CertificateOption{X509.WithExtKeyUsage(x509.ExtKeyUsageClientAuth)}, opts...))
+}
+
+// SignCertificateRequest verifies the signature of the CSR, and issues a leaf certificate of its public key with its subject and SANs.
+// The options are applied after them, so the key usages of a server certificate can be replaced by X509.WithExtKeyUsage, for example.
+func (ca *CA) SignCertificateRequest(csr *x509.CertificateRequest, opts ...X509CertificateOption) (cert *x509.Certificate, pemData []byte, err error) {
+	if err := csr.CheckSignature(); err != nil {
+		return nil, nil, fmt.Errorf("(*x509.CertificateRequest).CheckSignature: %w", err)
+	}
+
+	return ca.issue(csr.PublicKey, false, append([]X509CertificateOption{
+		X509.WithSubject(csr.Subject),
+		X509.WithDNSNames(csr.DNSNames...),
+		X509.WithIPAddresses(csr.IPAddresses...),
+		X509.WithURIs(csr.URIs...),
+		X509.WithEmailAddresses(csr.EmailAddresses...),
+	}, opts...))
+}
+
+func (ca *CA) issue(publicKey crypto.PublicKey, isCA bool, opts []X509CertificateOption) (cert *x509.Certificate, pemData []byte, err error) {
+	defaults := []X509CertificateOption{
+		X509.WithNotBefore(ca.now()),
+		func(o *x509CertificateOptions) { o.maxNotAfter = ca.cert.NotAfter },
+	}
+	if ca.policy.MaxValidity > 0 {
+		defaults = append(defaults, X509.WithValidity(ca.policy.MaxValidity))
+	}
+
+	template, err := X509.NewCertificateTemplate(publicKey, append(defaults, opts...)...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if template.IsCA != isCA {
+		return nil, nil, fmt.Errorf("isCA=%t: %w", template.IsCA, ErrX509PolicyViolation)
+	}
+
+	if err := ca.checkPolicy(template); err != nil {
+		return nil, nil, err
+	}
+
+	cert, pemData, err = X509.createCertificate(template, ca.cert, publicKey, ca.signer)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := ca.store.Save(cert); err != nil {
+		return nil, nil, fmt.Errorf("CAStore.Save: %w", err)
+	}
+
+	return cert, pemData, nil
+}
+
+func (ca *CA) checkPolicy(template *x509.Certificate) error {
+	notyet, _, expired, _ := X509.checkCertificate(ca.cert, ca.now())
+	if notyet {
+		return fmt.Errorf("CA notBefore=%s: %w", ca.cert.NotBefore, ErrX509CertificateIsNotYetValid)
+	}
+
+	if expired {
+		return fmt.Errorf("CA notAfter=%s: %w", ca.cert.NotAfter, ErrX509CertificateHasExpired)
+	}
+
+	if template.NotAfter.After(ca.cert.NotAfter) {
+		return fmt.Errorf("notAfter=%s exceeds CA notAfter=%s: %w", template.NotAfter, ca.cert.NotAfter, ErrX509PolicyViolation)
+	}
+
+	if validity := template.NotAfter.Sub(template.NotBefore); ca.policy.MaxValidity > 0 && validity > ca.policy.MaxValidity {
+		return fmt.Errorf("validity=%s exceeds max=%s: %w", validity, ca.policy.MaxValidity, ErrX509PolicyViolation)
+	}
+
+	if template.IsCA && x509HasMaxPathLen(ca.cert) && (!x509HasMaxPathLen(template) || template.MaxPathLen >= ca.cert.MaxPathLen) {
+		return fmt.Errorf("maxPathLen=%d exceeds CA maxPathLen=%d: %w", template.MaxPathLen, ca.cert.MaxPathLen, ErrX509PolicyViolation)
+	}
+
+	if err := ca.checkNames(template); err != nil {
+		return err
+	}
+
+	// The common name of a leaf certificate is checked as if it were a SAN, since peers may be authorized by it
+	// such as PeerAllowlist.CommonNames. That of a CA certificate names the CA itself, and is not checked.
+	if cn := template.Subject.CommonName; !template.IsCA && cn != "" {
+		if err := ca.checkNames(X509.applyCertificateOptions([]X509CertificateOption{X509.WithSANs(cn)}).template); err != nil {
+			return fmt.Errorf("commonName=%s: %w", cn, err)
+		}
+	}
+
+	return nil
+}
+
+// nolint: cyclop
+func (ca *CA) checkNames(template *x509.Certificate) error {
+	for _, name := range template.DNSNames {
+		if !x509AllowedBy(name, ca.policy.AllowedDNSNames, x509MatchDNSName) ||
+			!x509AllowedBy(name, ca.cert.PermittedDNSDomains, x509MatchDomain) ||
+			x509MatchAny(name, ca.cert.ExcludedDNSDomains, x509MatchDomain) {
+			return fmt.Errorf("dns=%s: %w", name, ErrX509PolicyViolation)
+		}
+	}
+
+	for _, ip := range template.IPAddresses {
+		if !x509AllowedBy(ip, ca.policy.AllowedIPRanges, x509MatchIPNet) ||
+			!x509AllowedBy(ip, ca.cert.PermittedIPRanges, x509MatchIPNet) ||
+			x509MatchAny(ip, ca.cert.ExcludedIPRanges, x509MatchIPNet) {
+			return fmt.Errorf("ip=%s: %w", ip, ErrX509PolicyViolation)
+		}
+	}
+
+	for _, uri := range template.URIs {
+		// A URI without a host cannot be matched against the name constraints, and is rejected if there are any.
+		constrained := len(ca.cert.PermittedURIDomains) > 0 || len(ca.cert.ExcludedURIDomains) > 0
+		if !x509AllowedBy(uri.String(), ca.policy.AllowedURIPrefixes, strings.HasPrefix) ||
+			(constrained && uri.Hostname() == "") ||
+			!x509AllowedBy(uri.Hostname(), ca.cert.PermittedURIDomains, x509MatchDomain) ||
+			x509MatchAny(uri.Hostname(), ca.cert.ExcludedURIDomains, x509MatchDomain) {
+			return fmt.Errorf("uri=%s: %w", uri, ErrX509PolicyViolation)
+		}
+	}
+
+	for _, email := range template.EmailAddresses {
+		_, domain, _ := strings.Cut(email, "@")
+		if !x509AllowedBy(domain, ca.policy.AllowedEmailDomains, x509MatchDomain) ||
+			!x509AllowedBy(email, ca.cert.PermittedEmailAddresses, x509MatchEmail) ||
+			x509MatchAny(email, ca.cert.ExcludedEmailAddresses, x509MatchEmail) {
+			return fmt.Errorf("email=%s: %w", email, ErrX509PolicyViolation)
+		}
+	}
+
+	return nil
+}
+
+// x509HasMaxPathLen reports whether the path length of the CA certificate is limited.
+func x509HasMaxPathLen(cert *x509.Certificate) bool {
+	return cert.MaxPathLen > 0 || (cert.MaxPathLen == 0 && cert.MaxPathLenZero)
+}
+
+// x509AllowedBy reports whether the value matches any of the constraints, or the constraints are empty.
+func x509AllowedBy[T, C any](value T, constraints []C, match func(T, C) bool) bool {
+	return len(constraints) == 0 || x509MatchAny(value, constraints, match)
+}
+
+func x509MatchAny[T, C any](value T, constraints []C, match func(T, C) bool) bool {
+	for _, constraint := range constraints {
+		if match(value, constraint) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// x509MatchDNSName reports whether the name matches the pattern, in which "*." matches any subdomains.
+func x509MatchDNSName(name, pattern string) bool {
+	name, pattern = strings.ToLower(name), strings.ToLower(pattern)
+	if strings.HasPrefix(pattern, "*.") {
+		return strings.HasSuffix(name, pattern[1:])
+	}
+
+	return name == pattern
+}
+
+// x509MatchDomain reports whether the name is in the domain in the same way as the name constraints of X.509,
+// in which "example.com" matches itself and its subdomains, and ".example.com" matches only its subdomains.
+func x509MatchDomain(name, domain string) bool {
+	name, domain = strings.ToLower(name), strings.ToLower(domain)
+	if strings.HasPrefix(domain, ".") {
+		return strings.HasSuffix(name, domain)
+	}
+
+	return name == domain || strings.HasSuffix(name, "."+domain)
+}
+
+// x509MatchEmail reports whether the email address matches the constraint in the same way as the name constraints of X.509,
+// in which a mailbox such as "admin@example.com" matches only itself, and a domain matches as x509MatchDomain does.
+func x509MatchEmail(email, constraint string) bool {
+	if local, domain, ok := strings.Cut(constraint, "@"); ok {
+		emailLocal, emailDomain, _ := strings.Cut(email, "@")
+
+		return emailLocal == local && strings.EqualFold(emailDomain, domain)
+	}
+
+	_, domain, _ := strings.Cut(email, "@")
+
+	return x509MatchDomain(domain, constraint)
+}
+
+func x509MatchIPNet(ip net.IP, ipNet *net.IPNet) bool {
+	return ipNet.Contains(ip)
+}
+
+// WithPermittedDNSDomains adds the name constraints of the DNS domains permitted for the certificates issued by the CA certificate.
+func (x509Utility) WithPermittedDNSDomains(domains ...string) X509CertificateOption {
+	return func(o *x509CertificateOptions) {
+		o.template.PermittedDNSDomains = append(o.template.PermittedDNSDomains, domains...)
+	}
+}
+
+// WithExcludedDNSDomains adds the name constraints of the DNS domains excluded from the certificates issued by the CA certificate.
+func (x509Utility) WithExcludedDNSDomains(domains ...string) X509CertificateOption {
+	return func(o *x509CertificateOptions) {
+		o.template.ExcludedDNSDomains = append(o.template.ExcludedDNSDomains, domains...)
+	}
+}
+
+// WithPermittedIPRanges adds the name constraints of the IP ranges permitted for the certificates issued by the CA certificate.
+func (x509Utility) WithPermittedIPRanges(ranges ...*net.IPNet) X509CertificateOption {
+	return func(o *x509CertificateOptions) {
+		o.template.PermittedIPRanges = append(o.template.PermittedIPRanges, ranges...)
+	}
+}
+
+// WithExcludedIPRanges adds the name constraints of the IP ranges excluded from the certificates issued by the CA certificate.
+func (x509Utility) WithExcludedIPRanges(ranges ...*net.IPNet) X509CertificateOption {
+	return func(o *x509CertificateOptions) {
+		o.template.ExcludedIPRanges = append(o.template.ExcludedIPRanges, ranges...)
+	}
+}
+
+// WithPermittedURIDomains adds the name constraints of the domains of the URI hosts permitted for the certificates issued by the CA certificate.
+func (x509Utility) WithPermittedURIDomains(domains ...string) X509CertificateOption {
+	return func(o *x509CertificateOptions) {
+		o.template.PermittedURIDomains = append(o.template.PermittedURIDomains, domains...)
+	}
+}
+
+// WithExcludedURIDomains adds the name constraints of the domains of the URI hosts excluded from the certificates issued by the CA certificate.
+func (x509Utility) WithExcludedURIDomains(domains ...string) X509CertificateOption {
+	return func(o *x509CertificateOptions) {
+		o.template.ExcludedURIDomains = append(o.template.ExcludedURIDomains, domains...)
+	}
+}
+
+// WithPermittedEmailAddresses adds the name constraints of the mailboxes or the domains permitted for the certificates issued by the CA certificate.
+func (x509Utility) WithPermittedEmailAddresses(addresses ...string) X509CertificateOption {
+	return func(o *x509CertificateOptions) {
+		o.template.PermittedEmailAddresses = append(o.template.PermittedEmailAddresses, addresses...)
+	}
+}
+
+// WithExcludedEmailAddresses adds the name constraints of the mailboxes or the domains excluded from the certificates issued by the CA certificate.
+func (x509Utility) WithExcludedEmailAddresses(addresses ...string) X509CertificateOption {
+	return func(o *x509CertificateOptions) {
+		o.template.ExcludedEmailAddresses = append(o.template.ExcludedEmailAddresses, addresses...)
+	}
+}
+
+// CreateCertificateRequest returns a PKCS#10 certificate signing request of the private key in both *x509.CertificateRequest and PEM.
+// Only the subject and the SANs of the options are used.
+func (x509Utility) CreateCertificateRequest(privateKey crypto.PrivateKey, opts ...X509CertificateOption) (csr *x509.CertificateRequest, pemData []byte, err error) {
+	template := X509.applyCertificateOptions(opts).template
+
+	der, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:        template.Subject,
+		DNSNames:       template.DNSNames,
+		EmailAddresses: template.EmailAddresses,
+		IPAddresses:    template.IPAddresses,
+		URIs:           template.URIs,
+	}, privateKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("x509.CreateCertificateRequest: %w", err)
+	}
+
+	csr, err = x509.ParseCertificateRequest(der)
+	if err != nil {
+		return nil, nil, fmt.Errorf("x509.ParseCertificateRequest: %w", err)
+	}
+
+	return csr, pem.EncodeToMemory(&pem.Block{Type: x509PEMTypeCertificateRequest, Bytes: der}), nil
+}
+
+// ParseCertificateRequestPEM returns *x509.CertificateRequest from the passed PEM data.
+func (x509Utility) ParseCertificateRequestPEM(pemData []byte) (*x509.CertificateRequest, error) {
+	block, _ := pem.Decode(pemData)
+	if block == nil {
+		return nil, ErrX509InvalidPEMFormat // nolint: wrapcheck
+	}
+
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("x509.ParseCertificateRequest: %w", err)
+	}
+
+	return csr, nil
+}
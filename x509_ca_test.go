@@ -0,0 +1,361 @@
+// nolint: testpackage
+package nits
+
+import (
+	"crypto"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+)
+
+func testNewRootCA(t *testing.T, opts ...CAOption) *CA {
+	t.Helper()
+
+	privateKey, err := Crypto.GenerateKey(CryptoECDSA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cert, _, err := X509.CreateSelfSigned(privateKey, X509.WithSubject(pkix.Name{CommonName: "root"}), X509.WithCA(-1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ca, err := X509.NewCA(cert, privateKey, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return ca
+}
+
+func testGeneratePublicKey(t *testing.T) crypto.PublicKey {
+	t.Helper()
+
+	privateKey, err := Crypto.GenerateKey(CryptoEd25519)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return privateKey.(crypto.Signer).Public()
+}
+
+func TestCA(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success(chain)", func(t *testing.T) {
+		t.Parallel()
+		root := testNewRootCA(t)
+
+		intermediateKey, err := Crypto.GenerateKey(CryptoECDSA256)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		intermediate, err := root.CreateIntermediate(intermediateKey,
+			X509.WithSubject(pkix.Name{CommonName: "intermediate"}),
+			X509.WithPermittedDNSDomains("internal.example.com"),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		cert, pemData, err := intermediate.IssueServerCertificate(testGeneratePublicKey(t), X509.WithDNSNames("app.internal.example.com"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if parsed, err := X509.ParseCertificatePEM(pemData); err != nil || !parsed.Equal(cert) {
+			t.Errorf("parsed != cert: %v", err)
+		}
+
+		chain := intermediate.Chain()
+		if len(chain) != 2 || chain[0] != intermediate.Certificate() || chain[1] != root.Certificate() {
+			t.Fatalf("unexpected chain: %v", chain)
+		}
+
+		roots, intermediates := x509.NewCertPool(), x509.NewCertPool()
+		roots.AddCert(root.Certificate())
+		intermediates.AddCert(intermediate.Certificate())
+
+		if _, err := cert.Verify(x509.VerifyOptions{DNSName: "app.internal.example.com", Roots: roots, Intermediates: intermediates}); err != nil {
+			t.Error(err)
+		}
+
+		certs, err := root.Store().List()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(certs) != 2 || !certs[0].Equal(intermediate.Certificate()) || !certs[1].Equal(cert) {
+			t.Errorf("unexpected store: %d certificates", len(certs))
+		}
+
+		if loaded, err := intermediate.Store().Load(cert.SerialNumber); err != nil || !loaded.Equal(cert) {
+			t.Errorf("loaded != cert: %v", err)
+		}
+	})
+
+	t.Run("success(CSR)", func(t *testing.T) {
+		t.Parallel()
+		ca := testNewRootCA(t)
+
+		privateKey, err := Crypto.GenerateKey(CryptoRSA2048)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, pemData, err := X509.CreateCertificateRequest(privateKey,
+			X509.WithSubject(pkix.Name{CommonName: "client"}),
+			X509.WithSANs("spiffe://example.org/client", "client@example.com"),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		csr, err := X509.ParseCertificateRequestPEM(pemData)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		cert, _, err := ca.SignCertificateRequest(csr, X509.WithExtKeyUsage(x509.ExtKeyUsageClientAuth), X509.WithValidity(time.Hour))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if cert.Subject.CommonName != "client" || len(cert.URIs) != 1 || len(cert.EmailAddresses) != 1 || cert.ExtKeyUsage[0] != x509.ExtKeyUsageClientAuth {
+			t.Errorf("unexpected certificate: subject=%s uris=%v emails=%v", cert.Subject, cert.URIs, cert.EmailAddresses)
+		}
+
+		if err := cert.CheckSignatureFrom(ca.Certificate()); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("success(NewCAPEM)", func(t *testing.T) {
+		t.Parallel()
+		privateKey, err := Crypto.GenerateKey(CryptoEd25519)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, certPEM, err := X509.CreateSelfSigned(privateKey, X509.WithCA(-1))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		keyPEM, err := X509.MarshalPKCSXPrivateKeyPEM(privateKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ca, err := X509.NewCAPEM(certPEM, keyPEM)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, _, err := ca.IssueClientCertificate(testGeneratePublicKey(t), X509.WithSANs("client")); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("failure(ErrX509PolicyViolation)", func(t *testing.T) {
+		t.Parallel()
+		_, ipNet, _ := net.ParseCIDR("10.0.0.0/8")
+		ca := testNewRootCA(t, X509.WithCAPolicy(CAPolicy{
+			MaxValidity:         24 * time.Hour,
+			AllowedDNSNames:     []string{"*.example.com"},
+			AllowedIPRanges:     []*net.IPNet{ipNet},
+			AllowedURIPrefixes:  []string{"spiffe://example.org/"},
+			AllowedEmailDomains: []string{"example.com"},
+		}))
+
+		if _, _, err := ca.Issue(testGeneratePublicKey(t), X509.WithSANs("a.example.com", "10.1.2.3", "spiffe://example.org/a", "a@mail.example.com"), X509.WithValidity(time.Hour)); err != nil {
+			t.Errorf("allowed: %v", err)
+		}
+
+		for name, opts := range map[string][]X509CertificateOption{
+			"validity": {X509.WithValidity(48 * time.Hour)},
+			"notAfter": {X509.WithNotAfter(ca.Certificate().NotAfter.Add(time.Second))},
+			"dns":      {X509.WithSANs("example.org")},
+			"ip":       {X509.WithSANs("192.0.2.1")},
+			"uri":      {X509.WithSANs("spiffe://example.com/a")},
+			"email":    {X509.WithSANs("a@example.org")},
+			"ca":       {X509.WithCA(0)},
+			"cn":       {X509.WithSubject(pkix.Name{CommonName: "admin"}), X509.WithSANs("a.example.com")},
+		} {
+			if _, _, err := ca.Issue(testGeneratePublicKey(t), append([]X509CertificateOption{X509.WithValidity(time.Hour)}, opts...)...); !errors.Is(err, ErrX509PolicyViolation) {
+				t.Errorf("%s: err != ErrX509PolicyViolation: %v", name, err)
+			}
+		}
+
+		certs, _ := ca.Store().List()
+		if len(certs) != 1 {
+			t.Errorf("violations should not be recorded: %d certificates", len(certs))
+		}
+	})
+
+	t.Run("failure(ErrX509PolicyViolation,NameConstraints)", func(t *testing.T) {
+		t.Parallel()
+		intermediateKey, err := Crypto.GenerateKey(CryptoEd25519)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, ipNet, _ := net.ParseCIDR("192.0.2.0/24")
+		intermediate, err := testNewRootCA(t).CreateIntermediate(intermediateKey,
+			X509.WithPermittedDNSDomains("example.com"),
+			X509.WithExcludedDNSDomains("secret.example.com"),
+			X509.WithExcludedIPRanges(ipNet),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, san := range []string{"example.org", "a.secret.example.com", "192.0.2.1"} {
+			if _, _, err := intermediate.Issue(testGeneratePublicKey(t), X509.WithSANs(san)); !errors.Is(err, ErrX509PolicyViolation) {
+				t.Errorf("%s: err != ErrX509PolicyViolation: %v", san, err)
+			}
+		}
+
+		constrained, err := testNewRootCA(t).CreateIntermediate(intermediateKey,
+			X509.WithPermittedURIDomains(".example.org"),
+			X509.WithExcludedURIDomains("secret.example.org"),
+			X509.WithPermittedEmailAddresses("example.com", "admin@example.org"),
+			X509.WithExcludedEmailAddresses("secret.example.com"),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, san := range []string{"spiffe://a.example.org/app", "a@example.com", "a@mail.example.com", "admin@EXAMPLE.org"} {
+			if _, _, err := constrained.Issue(testGeneratePublicKey(t), X509.WithSANs(san)); err != nil {
+				t.Errorf("%s: %v", san, err)
+			}
+		}
+
+		for _, san := range []string{"spiffe://example.org/app", "spiffe://a.secret.example.org/app", "spiffe:///app", "a@example.org", "a@secret.example.com"} {
+			if _, _, err := constrained.Issue(testGeneratePublicKey(t), X509.WithSANs(san)); !errors.Is(err, ErrX509PolicyViolation) {
+				t.Errorf("%s: err != ErrX509PolicyViolation: %v", san, err)
+			}
+		}
+	})
+
+	t.Run("failure(ErrX509PolicyViolation,CSR)", func(t *testing.T) {
+		t.Parallel()
+		ca := testNewRootCA(t, X509.WithCAPolicy(CAPolicy{AllowedDNSNames: []string{"*.example.com"}}))
+
+		privateKey, err := Crypto.GenerateKey(CryptoECDSA256)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for cn, allowed := range map[string]bool{"app.example.com": true, "admin": false, "admin.example.org": false} {
+			csr, _, err := X509.CreateCertificateRequest(privateKey, X509.WithSubject(pkix.Name{CommonName: cn}), X509.WithSANs("app.example.com"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, _, err := ca.SignCertificateRequest(csr); allowed && err != nil {
+				t.Errorf("%s: %v", cn, err)
+			} else if !allowed && !errors.Is(err, ErrX509PolicyViolation) {
+				t.Errorf("%s: err != ErrX509PolicyViolation: %v", cn, err)
+			}
+		}
+	})
+
+	t.Run("failure(ErrX509PolicyViolation,MaxPathLen)", func(t *testing.T) {
+		t.Parallel()
+		root := testNewRootCA(t)
+
+		privateKey, err := Crypto.GenerateKey(CryptoEd25519)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		limited, err := root.CreateIntermediate(privateKey, X509.WithCA(1))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for name, maxPathLen := range map[string]int{"unlimited": -1, "equal": 1, "greater": 2} {
+			if _, err := limited.CreateIntermediate(privateKey, X509.WithCA(maxPathLen)); !errors.Is(err, ErrX509PolicyViolation) {
+				t.Errorf("%s: err != ErrX509PolicyViolation: %v", name, err)
+			}
+		}
+
+		last, err := limited.CreateIntermediate(privateKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := last.CreateIntermediate(privateKey); !errors.Is(err, ErrX509PolicyViolation) {
+			t.Errorf("err != ErrX509PolicyViolation: %v", err)
+		}
+
+		if _, _, err := last.Issue(testGeneratePublicKey(t)); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("failure(ErrX509CertificateHasExpired)", func(t *testing.T) {
+		t.Parallel()
+		ca := testNewRootCA(t, X509.WithCAClock(func() time.Time { return time.Now().Add(2 * X509DefaultValidity) }))
+
+		if _, _, err := ca.Issue(testGeneratePublicKey(t)); !errors.Is(err, ErrX509CertificateHasExpired) {
+			t.Errorf("err != ErrX509CertificateHasExpired: %v", err)
+		}
+	})
+
+	t.Run("failure(NewCA)", func(t *testing.T) {
+		t.Parallel()
+		privateKey, err := Crypto.GenerateKey(CryptoEd25519)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		leaf, _, err := X509.CreateSelfSigned(privateKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := X509.NewCA(leaf, privateKey); !errors.Is(err, ErrX509CertificateIsNotCA) {
+			t.Errorf("err != ErrX509CertificateIsNotCA: %v", err)
+		}
+
+		otherKey, err := Crypto.GenerateKey(CryptoEd25519)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := X509.NewCA(testNewRootCA(t).Certificate(), otherKey); !errors.Is(err, ErrX509PrivateKeyDoesNotMatch) {
+			t.Errorf("err != ErrX509PrivateKeyDoesNotMatch: %v", err)
+		}
+
+		if _, err := X509.NewCAPEM([]byte("invalid"), nil); !errors.Is(err, ErrX509InvalidPEMFormat) {
+			t.Errorf("err != ErrX509InvalidPEMFormat: %v", err)
+		}
+	})
+
+	t.Run("failure(ErrX509CertificateAlreadyExists)", func(t *testing.T) {
+		t.Parallel()
+		store := X509.NewMemoryCAStore()
+		ca := testNewRootCA(t, X509.WithCAStore(store))
+
+		if _, _, err := ca.Issue(testGeneratePublicKey(t), X509.WithSerialNumber(ca.Certificate().SerialNumber)); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, _, err := ca.Issue(testGeneratePublicKey(t), X509.WithSerialNumber(ca.Certificate().SerialNumber)); !errors.Is(err, ErrX509CertificateAlreadyExists) {
+			t.Errorf("err != ErrX509CertificateAlreadyExists: %v", err)
+		}
+
+		if _, err := store.Load(new(big.Int).Add(ca.Certificate().SerialNumber, big.NewInt(1))); !errors.Is(err, ErrX509CertificateNotFound) {
+			t.Errorf("err != ErrX509CertificateNotFound: %v", err)
+		}
+	})
+}
@@ -0,0 +1,257 @@
+package nits
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrX509ChainVerificationFailed certificate chain verification failed.
+var ErrX509ChainVerificationFailed = errors.New("certificate chain verification failed")
+
+// X509ChainProblemReason is the reason of X509ChainProblem.
+type X509ChainProblemReason = string
+
+const (
+	// X509ChainMissingIntermediate means that the issuer of the certificate is neither in the bundle nor in the roots.
+	X509ChainMissingIntermediate X509ChainProblemReason = "missing_intermediate"
+	// X509ChainUnknownAuthority means that the root of the chain is not trusted by the roots.
+	X509ChainUnknownAuthority X509ChainProblemReason = "unknown_authority"
+	// X509ChainExpired means that the certificate has expired.
+	X509ChainExpired X509ChainProblemReason = "expired"
+	// X509ChainNotYetValid means that the certificate is not yet valid.
+	X509ChainNotYetValid X509ChainProblemReason = "not_yet_valid"
+	// X509ChainNameMismatch means that the leaf certificate is not valid for the DNS name.
+	X509ChainNameMismatch X509ChainProblemReason = "name_mismatch"
+	// X509ChainInvalid means any other problem reported by (*x509.Certificate).Verify, such as incompatible key usages.
+	X509ChainInvalid X509ChainProblemReason = "invalid"
+)
+
+// X509ChainProblem is a problem found by X509.VerifyChain.
+type X509ChainProblem struct {
+	Reason X509ChainProblemReason `json:"reason"`
+	// Index is the index of the certificate in X509ChainReport.Chain.
+	Index   int    `json:"index"`
+	Subject string `json:"subject"`
+	Detail  string `json:"detail"`
+}
+
+// X509ChainReport is the result of X509.VerifyChain.
+type X509ChainReport struct {
+	// Chain is the verified chain from the leaf to the root if the verification succeeded,
+	// or the bundle ordered from the leaf as far as the issuers are found otherwise.
+	Chain    []*x509.Certificate `json:"-"`
+	Problems []X509ChainProblem  `json:"problems"`
+}
+
+// Valid reports whether no problem was found.
+func (r *X509ChainReport) Valid() bool {
+	return len(r.Problems) == 0
+}
+
+func (r *X509ChainReport) add(reason X509ChainProblemReason, index int, detail string) {
+	for _, problem := range r.Problems {
+		if problem.Reason == reason && problem.Index == index {
+			return
+		}
+	}
+
+	r.Problems = append(r.Problems, X509ChainProblem{Reason: reason, Index: index, Subject: r.Chain[index].Subject.String(), Detail: detail})
+}
+
+// ParseCertificatesPEM returns all the certificates in the passed PEM data, such as fullchain.pem, in the order they appear.
+// The PEM blocks other than CERTIFICATE are skipped.
+func (x509Utility) ParseCertificatesPEM(pemData []byte) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+
+	for {
+		var block *pem.Block
+
+		block, pemData = pem.Decode(pemData)
+		if block == nil {
+			break
+		}
+
+		if block.Type != x509PEMTypeCertificate {
+			continue
+		}
+
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("x509.ParseCertificate: %w", err)
+		}
+
+		certs = append(certs, cert)
+	}
+
+	if len(certs) == 0 {
+		return nil, ErrX509InvalidPEMFormat // nolint: wrapcheck
+	}
+
+	return certs, nil
+}
+
+// OrderChain returns the certificates ordered from the leaf toward the root, following the issuers as far as they are found.
+// The leaf is the first certificate that has not issued any of the others. The certificates off the chain are dropped.
+func (x509Utility) OrderChain(certs []*x509.Certificate) []*x509.Certificate {
+	if len(certs) == 0 {
+		return nil
+	}
+
+	leaf := certs[0]
+
+	for _, cert := range certs {
+		issued := false
+
+		for _, other := range certs {
+			if other != cert && x509IsIssuer(other, cert) {
+				issued = true
+
+				break
+			}
+		}
+
+		if !issued {
+			leaf = cert
+
+			break
+		}
+	}
+
+	chain := []*x509.Certificate{leaf}
+	used := map[*x509.Certificate]bool{leaf: true}
+
+	for current := leaf; !x509IsSelfSigned(current); {
+		var parent *x509.Certificate
+
+		for _, cert := range certs {
+			if !used[cert] && x509IsIssuer(current, cert) {
+				parent = cert
+
+				break
+			}
+		}
+
+		if parent == nil {
+			break
+		}
+
+		chain = append(chain, parent)
+		used[parent] = true
+		current = parent
+	}
+
+	return chain
+}
+
+func x509IsIssuer(child, parent *x509.Certificate) bool {
+	return bytes.Equal(child.RawIssuer, parent.RawSubject) && child.CheckSignatureFrom(parent) == nil
+}
+
+func x509IsSelfSigned(cert *x509.Certificate) bool {
+	return bytes.Equal(cert.RawIssuer, cert.RawSubject) &&
+		cert.CheckSignature(cert.SignatureAlgorithm, cert.RawTBSCertificate, cert.Signature) == nil
+}
+
+// VerifyChain orders the certificates by X509.OrderChain and verifies the chain with the options, adding the certificates other than the leaf to opts.Intermediates.
+// If opts.Roots is nil, the system pool is used. If opts.DNSName is set, the leaf is checked for the hostname.
+// The report is always returned, and if verification fails, it explains why with X509ChainProblem, together with an error that wraps ErrX509ChainVerificationFailed.
+func (x509Utility) VerifyChain(certs []*x509.Certificate, opts x509.VerifyOptions) (*X509ChainReport, error) {
+	report := &X509ChainReport{Chain: X509.OrderChain(certs)}
+	if len(report.Chain) == 0 {
+		return report, fmt.Errorf("no certificates: %w", ErrX509ChainVerificationFailed)
+	}
+
+	leaf := report.Chain[0]
+
+	intermediates := x509.NewCertPool()
+	if opts.Intermediates != nil {
+		intermediates = opts.Intermediates.Clone()
+	}
+
+	for _, cert := range certs {
+		if cert != leaf {
+			intermediates.AddCert(cert)
+		}
+	}
+
+	opts.Intermediates = intermediates
+
+	chains, err := leaf.Verify(opts)
+	if err == nil {
+		report.Chain = chains[0]
+
+		return report, nil
+	}
+
+	X509.diagnoseChain(report, opts, err)
+
+	reasons := make([]string, 0, len(report.Problems))
+	for _, problem := range report.Problems {
+		reasons = append(reasons, fmt.Sprintf("%s[%d]", problem.Reason, problem.Index))
+	}
+
+	return report, fmt.Errorf("%s: %w", strings.Join(reasons, ", "), ErrX509ChainVerificationFailed)
+}
+
+// VerifyChainPEM is X509.VerifyChain for the certificates parsed by X509.ParseCertificatesPEM.
+func (x509Utility) VerifyChainPEM(pemData []byte, opts x509.VerifyOptions) (*X509ChainReport, error) {
+	certs, err := X509.ParseCertificatesPEM(pemData)
+	if err != nil {
+		return nil, fmt.Errorf("X509.ParseCertificatesPEM: %w", err)
+	}
+
+	return X509.VerifyChain(certs, opts)
+}
+
+func (x509Utility) diagnoseChain(report *X509ChainReport, opts x509.VerifyOptions, verifyErr error) {
+	now := opts.CurrentTime
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	for i, cert := range report.Chain {
+		switch notyet, _, expired, _ := X509.checkCertificate(cert, now); {
+		case notyet:
+			report.add(X509ChainNotYetValid, i, fmt.Sprintf("notBefore=%s", cert.NotBefore.Format(time.RFC3339)))
+		case expired:
+			report.add(X509ChainExpired, i, fmt.Sprintf("notAfter=%s", cert.NotAfter.Format(time.RFC3339)))
+		}
+	}
+
+	if opts.DNSName != "" {
+		if err := report.Chain[0].VerifyHostname(opts.DNSName); err != nil {
+			report.add(X509ChainNameMismatch, 0, err.Error())
+		}
+	}
+
+	var (
+		unknownAuthorityErr x509.UnknownAuthorityError
+		systemRootsErr      x509.SystemRootsError
+		hostnameErr         x509.HostnameError
+		invalidErr          x509.CertificateInvalidError
+	)
+
+	top := len(report.Chain) - 1
+
+	switch {
+	case errors.As(verifyErr, &unknownAuthorityErr), errors.As(verifyErr, &systemRootsErr):
+		if x509IsSelfSigned(report.Chain[top]) {
+			report.add(X509ChainUnknownAuthority, top, verifyErr.Error())
+		} else {
+			report.add(X509ChainMissingIntermediate, top, fmt.Sprintf("issuer %q is not found", report.Chain[top].Issuer))
+		}
+	case errors.As(verifyErr, &hostnameErr):
+		report.add(X509ChainNameMismatch, 0, verifyErr.Error())
+	case errors.As(verifyErr, &invalidErr) && invalidErr.Reason == x509.Expired:
+		if len(report.Problems) == 0 {
+			report.add(X509ChainExpired, 0, verifyErr.Error())
+		}
+	default:
+		report.add(X509ChainInvalid, 0, verifyErr.Error())
+	}
+}
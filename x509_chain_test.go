@@ -0,0 +1,163 @@
+// nolint: testpackage
+package nits
+
+import (
+	"bytes"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"testing"
+	"time"
+)
+
+type testChain struct {
+	root, intermediate, leaf *x509.Certificate
+	roots                    *x509.CertPool
+}
+
+func testNewChain(t *testing.T, opts ...X509CertificateOption) *testChain {
+	t.Helper()
+
+	root := testNewRootCA(t)
+
+	intermediateKey, err := Crypto.GenerateKey(CryptoECDSA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	intermediate, err := root.CreateIntermediate(intermediateKey, X509.WithSubject(pkix.Name{CommonName: "intermediate"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	leaf, _, err := intermediate.IssueServerCertificate(testGeneratePublicKey(t), append([]X509CertificateOption{X509.WithDNSNames("app.example.com")}, opts...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(root.Certificate())
+
+	return &testChain{root: root.Certificate(), intermediate: intermediate.Certificate(), leaf: leaf, roots: roots}
+}
+
+func TestX509Utility_ParseCertificatesPEM(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success()", func(t *testing.T) {
+		t.Parallel()
+		chain := testNewChain(t)
+		pemData := bytes.Join([][]byte{
+			X509.MarshalCertificatePEM(chain.leaf),
+			[]byte(testPKCS8KeyPEMString + "\n"),
+			X509.MarshalCertificatePEM(chain.intermediate),
+		}, nil)
+
+		certs, err := X509.ParseCertificatesPEM(pemData)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(certs) != 2 || !certs[0].Equal(chain.leaf) || !certs[1].Equal(chain.intermediate) {
+			t.Errorf("unexpected certificates: %d", len(certs))
+		}
+	})
+
+	t.Run("failure(ErrX509InvalidPEMFormat)", func(t *testing.T) {
+		t.Parallel()
+		if _, err := X509.ParseCertificatesPEM([]byte(testPKCS8KeyPEMString)); !errors.Is(err, ErrX509InvalidPEMFormat) {
+			t.Errorf("err != ErrX509InvalidPEMFormat: %v", err)
+		}
+	})
+}
+
+func TestX509Utility_VerifyChain(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success()", func(t *testing.T) {
+		t.Parallel()
+		chain := testNewChain(t)
+		pemData := bytes.Join([][]byte{
+			X509.MarshalCertificatePEM(chain.root),
+			X509.MarshalCertificatePEM(chain.leaf),
+			X509.MarshalCertificatePEM(chain.intermediate),
+		}, nil)
+
+		certs, err := X509.ParseCertificatesPEM(pemData)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if ordered := X509.OrderChain(certs); len(ordered) != 3 || ordered[0] != certs[1] || ordered[1] != certs[2] || ordered[2] != certs[0] {
+			t.Errorf("unexpected order: %v", ordered)
+		}
+
+		report, err := X509.VerifyChainPEM(pemData, x509.VerifyOptions{Roots: chain.roots, DNSName: "app.example.com"})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !report.Valid() || len(report.Chain) != 3 || !report.Chain[2].Equal(chain.root) {
+			t.Errorf("unexpected report: %+v", report)
+		}
+	})
+
+	tests := []struct {
+		name   string
+		reason X509ChainProblemReason
+		index  int
+		build  func(t *testing.T) ([]*x509.Certificate, x509.VerifyOptions)
+	}{
+		{"failure(missing_intermediate)", X509ChainMissingIntermediate, 0, func(t *testing.T) ([]*x509.Certificate, x509.VerifyOptions) {
+			chain := testNewChain(t)
+
+			return []*x509.Certificate{chain.leaf}, x509.VerifyOptions{Roots: chain.roots}
+		}},
+		{"failure(unknown_authority)", X509ChainUnknownAuthority, 2, func(t *testing.T) ([]*x509.Certificate, x509.VerifyOptions) {
+			chain := testNewChain(t)
+
+			return []*x509.Certificate{chain.leaf, chain.intermediate, chain.root}, x509.VerifyOptions{Roots: x509.NewCertPool()}
+		}},
+		{"failure(name_mismatch)", X509ChainNameMismatch, 0, func(t *testing.T) ([]*x509.Certificate, x509.VerifyOptions) {
+			chain := testNewChain(t)
+
+			return []*x509.Certificate{chain.leaf, chain.intermediate}, x509.VerifyOptions{Roots: chain.roots, DNSName: "other.example.com"}
+		}},
+		{"failure(expired)", X509ChainExpired, 0, func(t *testing.T) ([]*x509.Certificate, x509.VerifyOptions) {
+			now := time.Now()
+			chain := testNewChain(t, X509.WithNotBefore(now.Add(-2*time.Hour)), X509.WithNotAfter(now.Add(-time.Hour)))
+
+			return []*x509.Certificate{chain.leaf, chain.intermediate}, x509.VerifyOptions{Roots: chain.roots}
+		}},
+		{"failure(not_yet_valid)", X509ChainNotYetValid, 1, func(t *testing.T) ([]*x509.Certificate, x509.VerifyOptions) {
+			chain := testNewChain(t)
+
+			return []*x509.Certificate{chain.leaf, chain.intermediate}, x509.VerifyOptions{Roots: chain.roots, CurrentTime: chain.intermediate.NotBefore.Add(-time.Hour)}
+		}},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			certs, opts := tt.build(t)
+
+			report, err := X509.VerifyChain(certs, opts)
+			if !errors.Is(err, ErrX509ChainVerificationFailed) {
+				t.Fatalf("err != ErrX509ChainVerificationFailed: %v", err)
+			}
+
+			if report.Valid() {
+				t.Fatal("report.Valid() == true")
+			}
+
+			found := false
+			for _, problem := range report.Problems {
+				found = found || (problem.Reason == tt.reason && problem.Index == tt.index && problem.Subject != "")
+			}
+
+			if !found {
+				t.Errorf("%s[%d] is not reported: %+v", tt.reason, tt.index, report.Problems)
+			}
+		})
+	}
+}
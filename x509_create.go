@@ -0,0 +1,234 @@
+package nits
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"net/mail"
+	"net/url"
+	"time"
+)
+
+const (
+	// X509DefaultValidity is the validity of certificates created without X509.WithNotAfter or X509.WithValidity.
+	X509DefaultValidity = 365 * 24 * time.Hour
+
+	x509SerialNumberBits   = 128
+	x509PEMTypeCertificate = "CERTIFICATE"
+)
+
+// X509CertificateOption is an option for the certificates created by X509.CreateSelfSigned and CA.
+type X509CertificateOption func(*x509CertificateOptions)
+
+type x509CertificateOptions struct {
+	template         *x509.Certificate
+	validity         time.Duration
+	maxNotAfter      time.Time
+	keyUsageIsSet    bool
+	extKeyUsageIsSet bool
+}
+
+// WithSubject sets the subject.
+// If it is not set or its CommonName is empty, the CommonName is the first DNS name.
+func (x509Utility) WithSubject(subject pkix.Name) X509CertificateOption {
+	return func(o *x509CertificateOptions) { o.template.Subject = subject }
+}
+
+// WithDNSNames adds the DNS names to the subject alternative names.
+func (x509Utility) WithDNSNames(dnsNames ...string) X509CertificateOption {
+	return func(o *x509CertificateOptions) { o.template.DNSNames = append(o.template.DNSNames, dnsNames...) }
+}
+
+// WithIPAddresses adds the IP addresses to the subject alternative names.
+func (x509Utility) WithIPAddresses(ipAddresses ...net.IP) X509CertificateOption {
+	return func(o *x509CertificateOptions) {
+		o.template.IPAddresses = append(o.template.IPAddresses, ipAddresses...)
+	}
+}
+
+// WithURIs adds the URIs to the subject alternative names.
+func (x509Utility) WithURIs(uris ...*url.URL) X509CertificateOption {
+	return func(o *x509CertificateOptions) { o.template.URIs = append(o.template.URIs, uris...) }
+}
+
+// WithEmailAddresses adds the email addresses to the subject alternative names.
+func (x509Utility) WithEmailAddresses(emailAddresses ...string) X509CertificateOption {
+	return func(o *x509CertificateOptions) {
+		o.template.EmailAddresses = append(o.template.EmailAddresses, emailAddresses...)
+	}
+}
+
+// WithSANs adds the subject alternative names, classifying each of them into an IP address, a URI with a scheme, an email address or a DNS name.
+func (x509Utility) WithSANs(sans ...string) X509CertificateOption {
+	return func(o *x509CertificateOptions) {
+		for _, san := range sans {
+			if ip := net.ParseIP(san); ip != nil {
+				o.template.IPAddresses = append(o.template.IPAddresses, ip)
+
+				continue
+			}
+
+			if u, err := url.Parse(san); err == nil && u.Scheme != "" && u.Opaque == "" {
+				o.template.URIs = append(o.template.URIs, u)
+
+				continue
+			}
+
+			if addr, err := mail.ParseAddress(san); err == nil && addr.Address == san {
+				o.template.EmailAddresses = append(o.template.EmailAddresses, san)
+
+				continue
+			}
+
+			o.template.DNSNames = append(o.template.DNSNames, san)
+		}
+	}
+}
+
+// WithNotBefore sets the start of the validity. The default is the current time.
+func (x509Utility) WithNotBefore(notBefore time.Time) X509CertificateOption {
+	return func(o *x509CertificateOptions) { o.template.NotBefore = notBefore }
+}
+
+// WithNotAfter sets the end of the validity. It takes precedence over X509.WithValidity.
+func (x509Utility) WithNotAfter(notAfter time.Time) X509CertificateOption {
+	return func(o *x509CertificateOptions) { o.template.NotAfter = notAfter }
+}
+
+// WithValidity sets the length of the validity from NotBefore. The default is X509DefaultValidity.
+func (x509Utility) WithValidity(validity time.Duration) X509CertificateOption {
+	return func(o *x509CertificateOptions) { o.validity = validity }
+}
+
+// WithKeyUsage sets the key usage. The default is DigitalSignature, plus KeyEncipherment for RSA keys,
+// or CertSign and CRLSign for CA certificates.
+func (x509Utility) WithKeyUsage(keyUsage x509.KeyUsage) X509CertificateOption {
+	return func(o *x509CertificateOptions) {
+		o.template.KeyUsage = keyUsage
+		o.keyUsageIsSet = true
+	}
+}
+
+// WithExtKeyUsage sets the extended key usage. The default is ServerAuth, or none for CA certificates.
+func (x509Utility) WithExtKeyUsage(extKeyUsage ...x509.ExtKeyUsage) X509CertificateOption {
+	return func(o *x509CertificateOptions) {
+		o.template.ExtKeyUsage = extKeyUsage
+		o.extKeyUsageIsSet = true
+	}
+}
+
+// WithSerialNumber sets the serial number. The default is a random 128-bit number.
+func (x509Utility) WithSerialNumber(serialNumber *big.Int) X509CertificateOption {
+	return func(o *x509CertificateOptions) { o.template.SerialNumber = serialNumber }
+}
+
+// WithCA makes the certificate a CA certificate. If maxPathLen is negative, the path length is not limited.
+func (x509Utility) WithCA(maxPathLen int) X509CertificateOption {
+	return func(o *x509CertificateOptions) {
+		o.template.IsCA = true
+		o.template.MaxPathLen = maxPathLen
+		o.template.MaxPathLenZero = maxPathLen == 0
+	}
+}
+
+// NewCertificateTemplate returns the template of a certificate for the public key, applying the options and the defaults.
+func (x509Utility) NewCertificateTemplate(publicKey crypto.PublicKey, opts ...X509CertificateOption) (*x509.Certificate, error) {
+	o := X509.applyCertificateOptions(opts)
+	template := o.template
+
+	if template.SerialNumber == nil {
+		serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), x509SerialNumberBits))
+		if err != nil {
+			return nil, fmt.Errorf("rand.Int: %w", err)
+		}
+
+		template.SerialNumber = serialNumber
+	}
+
+	if template.NotBefore.IsZero() {
+		template.NotBefore = time.Now()
+	}
+
+	if template.NotAfter.IsZero() {
+		template.NotAfter = template.NotBefore.Add(o.validity)
+		if !o.maxNotAfter.IsZero() && template.NotAfter.After(o.maxNotAfter) {
+			template.NotAfter = o.maxNotAfter
+		}
+	}
+
+	if template.Subject.CommonName == "" && len(template.DNSNames) > 0 {
+		template.Subject.CommonName = template.DNSNames[0]
+	}
+
+	if !o.keyUsageIsSet {
+		switch {
+		case template.IsCA:
+			template.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature
+		default:
+			template.KeyUsage = x509.KeyUsageDigitalSignature
+			if _, ok := publicKey.(*rsa.PublicKey); ok {
+				template.KeyUsage |= x509.KeyUsageKeyEncipherment
+			}
+		}
+	}
+
+	if !o.extKeyUsageIsSet && !template.IsCA {
+		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+	}
+
+	return template, nil
+}
+
+func (x509Utility) applyCertificateOptions(opts []X509CertificateOption) *x509CertificateOptions {
+	o := &x509CertificateOptions{
+		template: &x509.Certificate{BasicConstraintsValid: true, MaxPathLen: -1},
+		validity: X509DefaultValidity,
+	}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// CreateSelfSigned returns a self-signed certificate of the private key in both *x509.Certificate and PEM.
+// The private key may be any signing key returned by Crypto.GenerateKey.
+func (x509Utility) CreateSelfSigned(privateKey crypto.PrivateKey, opts ...X509CertificateOption) (cert *x509.Certificate, pemData []byte, err error) {
+	signer, ok := privateKey.(crypto.Signer)
+	if !ok {
+		return nil, nil, fmt.Errorf("%T: %w", privateKey, ErrCryptoUnsupportedKeyType)
+	}
+
+	template, err := X509.NewCertificateTemplate(signer.Public(), opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return X509.createCertificate(template, template, signer.Public(), signer)
+}
+
+func (x509Utility) createCertificate(template, parent *x509.Certificate, publicKey crypto.PublicKey, signer crypto.Signer) (cert *x509.Certificate, pemData []byte, err error) {
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, publicKey, signer)
+	if err != nil {
+		return nil, nil, fmt.Errorf("x509.CreateCertificate: %w", err)
+	}
+
+	cert, err = x509.ParseCertificate(der)
+	if err != nil {
+		return nil, nil, fmt.Errorf("x509.ParseCertificate: %w", err)
+	}
+
+	return cert, X509.MarshalCertificatePEM(cert), nil
+}
+
+// MarshalCertificatePEM returns the PEM data of the certificate.
+func (x509Utility) MarshalCertificatePEM(cert *x509.Certificate) (pemData []byte) {
+	return pem.EncodeToMemory(&pem.Block{Type: x509PEMTypeCertificate, Bytes: cert.Raw})
+}
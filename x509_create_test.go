@@ -0,0 +1,140 @@
+// nolint: testpackage
+package nits
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"net"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestX509Utility_CreateSelfSigned(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		algorithm CryptographicAlgorithm
+	}{
+		{"success(CryptoRSA2048)", CryptoRSA2048},
+		{"success(CryptoECDSA256)", CryptoECDSA256},
+		{"success(CryptoECDSA384)", CryptoECDSA384},
+		{"success(CryptoEd25519)", CryptoEd25519},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			privateKey, err := Crypto.GenerateKey(tt.algorithm)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			cert, pemData, err := X509.CreateSelfSigned(privateKey, X509.WithDNSNames("example.com"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			parsed, err := X509.ParseCertificatePEM(pemData)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !parsed.Equal(cert) {
+				t.Error("parsed != cert")
+			}
+
+			if err := cert.CheckSignature(cert.SignatureAlgorithm, cert.RawTBSCertificate, cert.Signature); err != nil {
+				t.Error(err)
+			}
+
+			if cert.Subject.CommonName != "example.com" || cert.IsCA || cert.SerialNumber.Sign() <= 0 {
+				t.Errorf("unexpected certificate: subject=%s isCA=%t serial=%s", cert.Subject, cert.IsCA, cert.SerialNumber)
+			}
+
+			if notyet, _, expired, _ := X509.CheckCertificate(cert); notyet || expired {
+				t.Errorf("unexpected validity: notyet=%t expired=%t", notyet, expired)
+			}
+		})
+	}
+
+	t.Run("success(options)", func(t *testing.T) {
+		t.Parallel()
+		privateKey, err := Crypto.GenerateKey(CryptoECDSA256)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		notBefore := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+		spiffe := &url.URL{Scheme: "spiffe", Host: "example.org", Path: "/ns/default/sa/app"}
+
+		cert, _, err := X509.CreateSelfSigned(privateKey,
+			X509.WithSubject(pkix.Name{CommonName: "test", Organization: []string{"nits"}}),
+			X509.WithDNSNames("a.example.com"),
+			X509.WithIPAddresses(net.IPv4(192, 0, 2, 1)),
+			X509.WithURIs(spiffe),
+			X509.WithEmailAddresses("admin@example.com"),
+			X509.WithSANs("b.example.com", "2001:db8::1", "https://example.com/id", "user@example.com"),
+			X509.WithNotBefore(notBefore),
+			X509.WithValidity(24*time.Hour),
+			X509.WithKeyUsage(x509.KeyUsageDigitalSignature),
+			X509.WithExtKeyUsage(x509.ExtKeyUsageClientAuth),
+			X509.WithSerialNumber(big.NewInt(42)),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if cert.Subject.CommonName != "test" || len(cert.Subject.Organization) != 1 {
+			t.Errorf("unexpected subject: %s", cert.Subject)
+		}
+
+		if len(cert.DNSNames) != 2 || len(cert.IPAddresses) != 2 || len(cert.URIs) != 2 || len(cert.EmailAddresses) != 2 {
+			t.Errorf("unexpected SANs: dns=%v ip=%v uri=%v email=%v", cert.DNSNames, cert.IPAddresses, cert.URIs, cert.EmailAddresses)
+		}
+
+		if !cert.NotBefore.Equal(notBefore) || !cert.NotAfter.Equal(notBefore.Add(24*time.Hour)) {
+			t.Errorf("unexpected validity: %s - %s", cert.NotBefore, cert.NotAfter)
+		}
+
+		if cert.KeyUsage != x509.KeyUsageDigitalSignature || len(cert.ExtKeyUsage) != 1 || cert.ExtKeyUsage[0] != x509.ExtKeyUsageClientAuth {
+			t.Errorf("unexpected key usage: %v %v", cert.KeyUsage, cert.ExtKeyUsage)
+		}
+
+		if cert.SerialNumber.Int64() != 42 {
+			t.Errorf("unexpected serial number: %s", cert.SerialNumber)
+		}
+	})
+
+	t.Run("success(WithCA)", func(t *testing.T) {
+		t.Parallel()
+		privateKey, err := Crypto.GenerateKey(CryptoEd25519)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		cert, _, err := X509.CreateSelfSigned(privateKey, X509.WithSubject(pkix.Name{CommonName: "root"}), X509.WithCA(0))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !cert.IsCA || !cert.MaxPathLenZero || cert.KeyUsage&x509.KeyUsageCertSign == 0 || len(cert.ExtKeyUsage) != 0 {
+			t.Errorf("unexpected CA certificate: isCA=%t maxPathLenZero=%t keyUsage=%v extKeyUsage=%v", cert.IsCA, cert.MaxPathLenZero, cert.KeyUsage, cert.ExtKeyUsage)
+		}
+	})
+
+	t.Run("failure(ErrCryptoUnsupportedKeyType)", func(t *testing.T) {
+		t.Parallel()
+		privateKey, err := Crypto.GenerateKey(CryptoX25519)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, _, err := X509.CreateSelfSigned(privateKey); !errors.Is(err, ErrCryptoUnsupportedKeyType) {
+			t.Errorf("err != ErrCryptoUnsupportedKeyType: %v", err)
+		}
+	})
+}
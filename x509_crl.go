@@ -0,0 +1,277 @@
+package nits
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// ErrX509CRLIssuerMismatch CRL issuer does not match certificate issuer.
+var ErrX509CRLIssuerMismatch = errors.New("CRL issuer does not match certificate issuer")
+
+// Revocation reason codes defined in RFC 5280 5.3.1.
+const (
+	X509RevocationReasonUnspecified          = 0
+	X509RevocationReasonKeyCompromise        = 1
+	X509RevocationReasonCACompromise         = 2
+	X509RevocationReasonAffiliationChanged   = 3
+	X509RevocationReasonSuperseded           = 4
+	X509RevocationReasonCessationOfOperation = 5
+	X509RevocationReasonCertificateHold      = 6
+	X509RevocationReasonRemoveFromCRL        = 8
+	X509RevocationReasonPrivilegeWithdrawn   = 9
+	X509RevocationReasonAACompromise         = 10
+
+	// X509DefaultCRLValidity is the default length from ThisUpdate to NextUpdate of CRLs.
+	X509DefaultCRLValidity = 7 * 24 * time.Hour
+
+	x509PEMTypeCRL = "X509 CRL"
+)
+
+// nolint: gochecknoglobals
+var x509RevocationReasons = map[int]string{
+	X509RevocationReasonUnspecified:          "unspecified",
+	X509RevocationReasonKeyCompromise:        "keyCompromise",
+	X509RevocationReasonCACompromise:         "cACompromise",
+	X509RevocationReasonAffiliationChanged:   "affiliationChanged",
+	X509RevocationReasonSuperseded:           "superseded",
+	X509RevocationReasonCessationOfOperation: "cessationOfOperation",
+	X509RevocationReasonCertificateHold:      "certificateHold",
+	X509RevocationReasonRemoveFromCRL:        "removeFromCRL",
+	X509RevocationReasonPrivilegeWithdrawn:   "privilegeWithdrawn",
+	X509RevocationReasonAACompromise:         "aACompromise",
+}
+
+// RevocationReason returns the name of the reason code defined in RFC 5280, such as "keyCompromise".
+func (x509Utility) RevocationReason(code int) string {
+	if reason, ok := x509RevocationReasons[code]; ok {
+		return reason
+	}
+
+	return fmt.Sprintf("unknown(%d)", code)
+}
+
+type crlOptions struct {
+	number     *big.Int
+	thisUpdate time.Time
+	nextUpdate time.Time
+	validity   time.Duration
+}
+
+// CRLOption is an option for X509.CreateCRL.
+type CRLOption func(*crlOptions)
+
+// WithCRLNumber sets the CRL number, which must increase for each CRL of the issuer.
+// The default is ThisUpdate in nanoseconds since the epoch, so CRLs created at the same ThisUpdate share the number.
+// (*CA).CreateCRL increases it over the CRLs that the CA created.
+func (x509Utility) WithCRLNumber(number *big.Int) CRLOption {
+	return func(o *crlOptions) { o.number = number }
+}
+
+// WithCRLThisUpdate sets ThisUpdate. The default is the current time.
+func (x509Utility) WithCRLThisUpdate(thisUpdate time.Time) CRLOption {
+	return func(o *crlOptions) { o.thisUpdate = thisUpdate }
+}
+
+// WithCRLNextUpdate sets NextUpdate. It takes precedence over X509.WithCRLValidity.
+func (x509Utility) WithCRLNextUpdate(nextUpdate time.Time) CRLOption {
+	return func(o *crlOptions) { o.nextUpdate = nextUpdate }
+}
+
+// WithCRLValidity sets the length from ThisUpdate to NextUpdate. The default is X509DefaultCRLValidity.
+func (x509Utility) WithCRLValidity(validity time.Duration) CRLOption {
+	return func(o *crlOptions) { o.validity = validity }
+}
+
+// CreateCRL returns a CRL of the revoked certificates signed by the issuer in both *x509.RevocationList and PEM.
+// The issuer must be a CA certificate with the CRLSign key usage, and the private key must be its key.
+func (x509Utility) CreateCRL(issuer *x509.Certificate, privateKey crypto.PrivateKey, revoked []x509.RevocationListEntry, opts ...CRLOption) (crl *x509.RevocationList, pemData []byte, err error) {
+	signer, ok := privateKey.(crypto.Signer)
+	if !ok {
+		return nil, nil, fmt.Errorf("%T: %w", privateKey, ErrCryptoUnsupportedKeyType)
+	}
+
+	o := &crlOptions{validity: X509DefaultCRLValidity}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if o.thisUpdate.IsZero() {
+		o.thisUpdate = time.Now()
+	}
+
+	if o.nextUpdate.IsZero() {
+		o.nextUpdate = o.thisUpdate.Add(o.validity)
+	}
+
+	if o.number == nil {
+		o.number = big.NewInt(o.thisUpdate.UnixNano())
+	}
+
+	der, err := x509.CreateRevocationList(rand.Reader, &x509.RevocationList{
+		Number:                    o.number,
+		ThisUpdate:                o.thisUpdate,
+		NextUpdate:                o.nextUpdate,
+		RevokedCertificateEntries: revoked,
+	}, issuer, signer)
+	if err != nil {
+		return nil, nil, fmt.Errorf("x509.CreateRevocationList: %w", err)
+	}
+
+	crl, err = x509.ParseRevocationList(der)
+	if err != nil {
+		return nil, nil, fmt.Errorf("x509.ParseRevocationList: %w", err)
+	}
+
+	return crl, pem.EncodeToMemory(&pem.Block{Type: x509PEMTypeCRL, Bytes: der}), nil
+}
+
+// CreateCRL returns a CRL of the revoked certificates signed by the CA, with ThisUpdate from the clock of the CA.
+// Unless X509.WithCRLNumber is passed, the CRL number is greater than that of any CRL the CA created before,
+// even if they have the same ThisUpdate.
+func (ca *CA) CreateCRL(revoked []x509.RevocationListEntry, opts ...CRLOption) (crl *x509.RevocationList, pemData []byte, err error) {
+	opts = append([]CRLOption{X509.WithCRLThisUpdate(ca.now())}, opts...)
+
+	o := &crlOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	ca.crlMu.Lock()
+	defer ca.crlMu.Unlock()
+
+	number := o.number
+	if number == nil {
+		number = big.NewInt(o.thisUpdate.UnixNano())
+		if ca.crlNumber != nil && number.Cmp(ca.crlNumber) <= 0 {
+			number = new(big.Int).Add(ca.crlNumber, big.NewInt(1))
+		}
+	}
+
+	crl, pemData, err = X509.CreateCRL(ca.cert, ca.signer, revoked, append(opts, X509.WithCRLNumber(number))...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if ca.crlNumber == nil || number.Cmp(ca.crlNumber) > 0 {
+		ca.crlNumber = number
+	}
+
+	return crl, pemData, nil
+}
+
+// ParseCRL returns *x509.RevocationList from the passed PEM or DER data.
+func (x509Utility) ParseCRL(data []byte) (*x509.RevocationList, error) {
+	if block, _ := pem.Decode(data); block != nil {
+		data = block.Bytes
+	}
+
+	crl, err := x509.ParseRevocationList(data)
+	if err != nil {
+		return nil, fmt.Errorf("x509.ParseRevocationList: %v: %w", err, ErrX509InvalidPEMFormat)
+	}
+
+	return crl, nil
+}
+
+// CRLState is the state of CRLStatus.
+type CRLState = string
+
+const (
+	// CRLStateFresh means that the current time is between ThisUpdate and NextUpdate.
+	CRLStateFresh CRLState = "fresh"
+	// CRLStateStale means that the current time is after NextUpdate, so a newer CRL should be fetched.
+	CRLStateStale CRLState = "stale"
+	// CRLStateNotYetValid means that the current time is before ThisUpdate.
+	CRLStateNotYetValid CRLState = "not_yet_valid"
+)
+
+// CRLStatus is the freshness of a CRL at CheckedAt.
+type CRLStatus struct {
+	State      CRLState  `json:"state"`
+	CheckedAt  time.Time `json:"checkedAt"`
+	Number     *big.Int  `json:"number,omitempty"`
+	ThisUpdate time.Time `json:"thisUpdate"`
+	// NextUpdate is zero if the CRL does not have it, in which case the CRL never gets stale.
+	NextUpdate time.Time `json:"nextUpdate,omitzero"`
+}
+
+// RevocationStatus is the revocation status of a certificate in a CRL.
+type RevocationStatus struct {
+	Revoked bool `json:"revoked"`
+	// RevocationTime, ReasonCode and Reason are set only if Revoked is true.
+	RevocationTime time.Time `json:"revocationTime,omitzero"`
+	ReasonCode     int       `json:"reasonCode"`
+	Reason         string    `json:"reason,omitempty"`
+	// CRL is the freshness of the CRL checked. A stale CRL may miss recent revocations.
+	CRL *CRLStatus `json:"crl"`
+}
+
+type revocationOptions struct {
+	now func() time.Time
+}
+
+// RevocationOption is an option for X509.CRLStatus and X509.CheckRevocation.
+type RevocationOption func(*revocationOptions)
+
+// WithRevocationClock replaces time.Now used to check the freshness.
+func (x509Utility) WithRevocationClock(now func() time.Time) RevocationOption {
+	return func(o *revocationOptions) { o.now = now }
+}
+
+// CRLStatus returns the freshness of the CRL.
+func (x509Utility) CRLStatus(crl *x509.RevocationList, opts ...RevocationOption) *CRLStatus {
+	o := &revocationOptions{now: time.Now}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	now := o.now()
+	status := &CRLStatus{CheckedAt: now, Number: crl.Number, ThisUpdate: crl.ThisUpdate, NextUpdate: crl.NextUpdate}
+
+	switch {
+	case now.Before(crl.ThisUpdate):
+		status.State = CRLStateNotYetValid
+	case !crl.NextUpdate.IsZero() && now.After(crl.NextUpdate):
+		status.State = CRLStateStale
+	default:
+		status.State = CRLStateFresh
+	}
+
+	return status
+}
+
+// CheckRevocation verifies that the CRL is signed by the issuer of the certificate, and returns whether the certificate is revoked in it,
+// together with the freshness of the CRL. The entries with the removeFromCRL reason are regarded as not revoked.
+func (x509Utility) CheckRevocation(cert, issuer *x509.Certificate, crl *x509.RevocationList, opts ...RevocationOption) (*RevocationStatus, error) {
+	if !bytes.Equal(cert.RawIssuer, issuer.RawSubject) || !bytes.Equal(crl.RawIssuer, issuer.RawSubject) {
+		return nil, fmt.Errorf("certificate issuer=%s CRL issuer=%s: %w", cert.Issuer, crl.Issuer, ErrX509CRLIssuerMismatch)
+	}
+
+	if err := crl.CheckSignatureFrom(issuer); err != nil {
+		return nil, fmt.Errorf("(*x509.RevocationList).CheckSignatureFrom: %w", err)
+	}
+
+	status := &RevocationStatus{CRL: X509.CRLStatus(crl, opts...)}
+
+	for _, entry := range crl.RevokedCertificateEntries {
+		if entry.SerialNumber.Cmp(cert.SerialNumber) != 0 || entry.ReasonCode == X509RevocationReasonRemoveFromCRL {
+			continue
+		}
+
+		status.Revoked = true
+		status.RevocationTime = entry.RevocationTime
+		status.ReasonCode = entry.ReasonCode
+		status.Reason = X509.RevocationReason(entry.ReasonCode)
+
+		break
+	}
+
+	return status, nil
+}
@@ -0,0 +1,197 @@
+// nolint: testpackage
+package nits
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestX509Utility_CheckRevocation(t *testing.T) {
+	t.Parallel()
+
+	ca := testNewRootCA(t)
+	revokedCert, _, err := ca.Issue(testGeneratePublicKey(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	validCert, validPEM, err := ca.Issue(testGeneratePublicKey(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	revocationTime := time.Now().Add(-time.Hour).Truncate(time.Second)
+	crl, pemData, err := ca.CreateCRL([]x509.RevocationListEntry{
+		{SerialNumber: revokedCert.SerialNumber, RevocationTime: revocationTime, ReasonCode: X509RevocationReasonKeyCompromise},
+	}, X509.WithCRLNumber(big.NewInt(2)), X509.WithCRLValidity(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := X509.ParseCRL(pemData)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if parsedDER, err := X509.ParseCRL(crl.Raw); err != nil || parsedDER.Number.Int64() != 2 || parsed.Number.Int64() != 2 {
+		t.Fatalf("unexpected CRL: %v", err)
+	}
+
+	t.Run("success(Revoked)", func(t *testing.T) {
+		t.Parallel()
+		status, err := X509.CheckRevocation(revokedCert, ca.Certificate(), parsed)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !status.Revoked || !status.RevocationTime.Equal(revocationTime) || status.ReasonCode != X509RevocationReasonKeyCompromise || status.Reason != "keyCompromise" {
+			t.Errorf("unexpected status: %+v", status)
+		}
+
+		if status.CRL.State != CRLStateFresh {
+			t.Errorf("unexpected CRL status: %+v", status.CRL)
+		}
+	})
+
+	t.Run("success(NotRevoked)", func(t *testing.T) {
+		t.Parallel()
+		cert, err := X509.ParseCertificatePEM(validPEM)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		status, err := X509.CheckRevocation(cert, ca.Certificate(), parsed)
+		if err != nil || status.Revoked || !cert.Equal(validCert) {
+			t.Errorf("unexpected status: %+v: %v", status, err)
+		}
+	})
+
+	t.Run("success(Stale)", func(t *testing.T) {
+		t.Parallel()
+		clock := X509.WithRevocationClock(func() time.Time { return time.Now().Add(2 * time.Hour) })
+
+		status, err := X509.CheckRevocation(revokedCert, ca.Certificate(), parsed, clock)
+		if err != nil || !status.Revoked || status.CRL.State != CRLStateStale {
+			t.Errorf("unexpected status: %+v: %v", status, err)
+		}
+
+		if status := X509.CRLStatus(parsed, X509.WithRevocationClock(func() time.Time { return parsed.ThisUpdate.Add(-time.Second) })); status.State != CRLStateNotYetValid {
+			t.Errorf("unexpected CRL status: %+v", status)
+		}
+	})
+
+	t.Run("failure(ErrX509CRLIssuerMismatch)", func(t *testing.T) {
+		t.Parallel()
+		otherKey, err := Crypto.GenerateKey(CryptoEd25519)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		other, _, err := X509.CreateSelfSigned(otherKey, X509.WithSubject(pkix.Name{CommonName: "other"}), X509.WithCA(-1))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		otherCRL, _, err := X509.CreateCRL(other, otherKey, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := X509.CheckRevocation(revokedCert, ca.Certificate(), otherCRL); !errors.Is(err, ErrX509CRLIssuerMismatch) {
+			t.Errorf("err != ErrX509CRLIssuerMismatch: %v", err)
+		}
+	})
+
+	t.Run("failure(Signature)", func(t *testing.T) {
+		t.Parallel()
+		tampered := *parsed
+		tampered.Signature = append([]byte{}, parsed.Signature...)
+		tampered.Signature[len(tampered.Signature)-1] ^= 0xff
+
+		if _, err := X509.CheckRevocation(revokedCert, ca.Certificate(), &tampered); err == nil {
+			t.Error("err == nil")
+		}
+	})
+
+	t.Run("failure(ErrX509InvalidPEMFormat)", func(t *testing.T) {
+		t.Parallel()
+		if _, err := X509.ParseCRL([]byte("invalid")); !errors.Is(err, ErrX509InvalidPEMFormat) {
+			t.Errorf("err != ErrX509InvalidPEMFormat: %v", err)
+		}
+	})
+
+	t.Run("success(CRLNumber)", func(t *testing.T) {
+		t.Parallel()
+		now := time.Now()
+		ca := testNewRootCA(t, X509.WithCAClock(func() time.Time { return now }))
+
+		first, _, err := ca.CreateCRL(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		second, _, err := ca.CreateCRL(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if first.Number.Int64() != now.UnixNano() || second.Number.Cmp(first.Number) <= 0 {
+			t.Errorf("unexpected numbers: %s %s", first.Number, second.Number)
+		}
+
+		if _, _, err := ca.CreateCRL(nil, X509.WithCRLNumber(big.NewInt(now.UnixNano()+100))); err != nil {
+			t.Fatal(err)
+		}
+
+		third, _, err := ca.CreateCRL(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if third.Number.Int64() != now.UnixNano()+101 {
+			t.Errorf("unexpected number: %s", third.Number)
+		}
+	})
+
+	t.Run("failure(CreateCRL)", func(t *testing.T) {
+		t.Parallel()
+		leafKey, err := Crypto.GenerateKey(CryptoEd25519)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		leaf, _, err := X509.CreateSelfSigned(leafKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, _, err := X509.CreateCRL(leaf, leafKey, nil); err == nil {
+			t.Error("err == nil")
+		}
+	})
+}
+
+func TestX509Utility_RevocationReason(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		reason int
+		want   string
+	}{
+		{"success(X509RevocationReasonCertificateHold)", X509RevocationReasonCertificateHold, "certificateHold"},
+		{"success(unknown)", 7, "unknown(7)"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := X509.RevocationReason(tt.reason); got != tt.want {
+				t.Errorf("X509.RevocationReason() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,598 @@
+package nits
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ocsp"
+)
+
+var (
+	// ErrX509CertificateHasNoOCSPServer certificate has no OCSP server.
+	ErrX509CertificateHasNoOCSPServer = errors.New("certificate has no OCSP server")
+
+	// ErrX509OCSPRequestIsNotForIssuer OCSP request is not for issuer.
+	ErrX509OCSPRequestIsNotForIssuer = errors.New("OCSP request is not for issuer")
+
+	// ErrX509OCSPServerFailed OCSP server failed.
+	ErrX509OCSPServerFailed = errors.New("OCSP server failed")
+
+	// ErrX509OCSPResponderIsNotAuthorized OCSP responder is not authorized.
+	ErrX509OCSPResponderIsNotAuthorized = errors.New("OCSP responder is not authorized")
+
+	// ErrX509OCSPResponseIsNotCurrent OCSP response is not current.
+	ErrX509OCSPResponseIsNotCurrent = errors.New("OCSP response is not current")
+)
+
+const (
+	// X509DefaultOCSPResponseValidity is the default length from ThisUpdate to NextUpdate of OCSP responses.
+	X509DefaultOCSPResponseValidity = 24 * time.Hour
+
+	x509OCSPRequestContentType  = "application/ocsp-request"
+	x509OCSPResponseContentType = "application/ocsp-response"
+	x509OCSPMaxRequestSize      = 10 * 1024
+	// x509OCSPMaxClockSkew is how far ThisUpdate of a response may be ahead of the clock.
+	x509OCSPMaxClockSkew = 5 * time.Minute
+)
+
+// OCSPCertStatus is the certificate status of OCSPStatus.
+type OCSPCertStatus = string
+
+const (
+	// OCSPCertStatusGood means that the certificate is not revoked.
+	OCSPCertStatusGood OCSPCertStatus = "good"
+	// OCSPCertStatusRevoked means that the certificate is revoked.
+	OCSPCertStatusRevoked OCSPCertStatus = "revoked"
+	// OCSPCertStatusUnknown means that the responder does not know the certificate.
+	OCSPCertStatusUnknown OCSPCertStatus = "unknown"
+)
+
+// nolint: gochecknoglobals
+var x509OCSPCertStatuses = map[int]OCSPCertStatus{
+	ocsp.Good:    OCSPCertStatusGood,
+	ocsp.Revoked: OCSPCertStatusRevoked,
+	ocsp.Unknown: OCSPCertStatusUnknown,
+}
+
+// OCSPStatus is the status of a certificate in a verified OCSP response.
+type OCSPStatus struct {
+	Status OCSPCertStatus `json:"status"`
+	// RevocationTime, ReasonCode and Reason are set only if Status is OCSPCertStatusRevoked.
+	RevocationTime time.Time `json:"revocationTime,omitzero"`
+	ReasonCode     int       `json:"reasonCode"`
+	Reason         string    `json:"reason,omitempty"`
+	ProducedAt     time.Time `json:"producedAt"`
+	ThisUpdate     time.Time `json:"thisUpdate"`
+	// NextUpdate is zero if the response does not have it, in which case newer information is always available.
+	NextUpdate time.Time `json:"nextUpdate,omitzero"`
+	// Response is the parsed response.
+	Response *ocsp.Response `json:"-"`
+}
+
+// CreateOCSPRequest returns a DER-encoded OCSP request for the certificate issued by the issuer.
+func (x509Utility) CreateOCSPRequest(cert, issuer *x509.Certificate) ([]byte, error) {
+	der, err := ocsp.CreateRequest(cert, issuer, nil)
+	if err != nil {
+		return nil, fmt.Errorf("ocsp.CreateRequest: %w", err)
+	}
+
+	return der, nil
+}
+
+// ParseOCSPRequest returns *ocsp.Request from the DER-encoded OCSP request.
+func (x509Utility) ParseOCSPRequest(der []byte) (*ocsp.Request, error) {
+	req, err := ocsp.ParseRequest(der)
+	if err != nil {
+		return nil, fmt.Errorf("ocsp.ParseRequest: %w", err)
+	}
+
+	return req, nil
+}
+
+// CreateOCSPResponse returns a DER-encoded OCSP response of the template signed by the responder.
+// The responder is either the issuer itself or a certificate issued by the issuer with the OCSPSigning extended key usage,
+// and its key must be RSA or ECDSA.
+func (x509Utility) CreateOCSPResponse(issuer, responderCert *x509.Certificate, responderKey crypto.PrivateKey, template ocsp.Response) ([]byte, error) {
+	signer, ok := responderKey.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("%T: %w", responderKey, ErrCryptoUnsupportedKeyType)
+	}
+
+	if !responderCert.Equal(issuer) {
+		template.Certificate = responderCert
+	}
+
+	der, err := ocsp.CreateResponse(issuer, responderCert, template, signer)
+	if err != nil {
+		return nil, fmt.Errorf("ocsp.CreateResponse: %w", err)
+	}
+
+	return der, nil
+}
+
+// ParseOCSPResponse verifies the DER-encoded OCSP response for the certificate issued by the issuer, and returns the status in it.
+// If the response is signed by a delegated responder, its certificate must have the OCSPSigning extended key usage,
+// or the error wraps ErrX509OCSPResponderIsNotAuthorized, and must be valid at the current time.
+// The response must also be current: ThisUpdate must not be ahead of the current time by more than 5 minutes,
+// and NextUpdate, if any, must be after the current time, or the error wraps ErrX509OCSPResponseIsNotCurrent,
+// so that an old response cannot be replayed after the certificate is revoked.
+func (x509Utility) ParseOCSPResponse(der []byte, cert, issuer *x509.Certificate) (*OCSPStatus, error) {
+	return X509.parseOCSPResponse(der, cert, issuer, time.Now())
+}
+
+func (x509Utility) parseOCSPResponse(der []byte, cert, issuer *x509.Certificate, now time.Time) (*OCSPStatus, error) {
+	resp, err := ocsp.ParseResponseForCert(der, cert, issuer)
+	if err != nil {
+		return nil, fmt.Errorf("ocsp.ParseResponseForCert: %w", err)
+	}
+
+	if responder := resp.Certificate; responder != nil && !responder.Equal(issuer) {
+		if !slices.Contains(responder.ExtKeyUsage, x509.ExtKeyUsageOCSPSigning) {
+			return nil, fmt.Errorf("responder subject=%s: %w", responder.Subject, ErrX509OCSPResponderIsNotAuthorized)
+		}
+
+		notyet, _, expired, _ := X509.checkCertificate(responder, now)
+		if notyet {
+			return nil, fmt.Errorf("responder notBefore=%s: %w", responder.NotBefore, ErrX509CertificateIsNotYetValid)
+		}
+
+		if expired {
+			return nil, fmt.Errorf("responder notAfter=%s: %w", responder.NotAfter, ErrX509CertificateHasExpired)
+		}
+	}
+
+	if resp.ThisUpdate.After(now.Add(x509OCSPMaxClockSkew)) {
+		return nil, fmt.Errorf("thisUpdate=%s: %w", resp.ThisUpdate, ErrX509OCSPResponseIsNotCurrent)
+	}
+
+	if !resp.NextUpdate.IsZero() && !resp.NextUpdate.After(now) {
+		return nil, fmt.Errorf("nextUpdate=%s: %w", resp.NextUpdate, ErrX509OCSPResponseIsNotCurrent)
+	}
+
+	status := &OCSPStatus{
+		Status:     x509OCSPCertStatuses[resp.Status],
+		ProducedAt: resp.ProducedAt,
+		ThisUpdate: resp.ThisUpdate,
+		NextUpdate: resp.NextUpdate,
+		Response:   resp,
+	}
+
+	if resp.Status == ocsp.Revoked {
+		status.RevocationTime = resp.RevokedAt
+		status.ReasonCode = resp.RevocationReason
+		status.Reason = X509.RevocationReason(resp.RevocationReason)
+	}
+
+	return status, nil
+}
+
+// RevocationStore answers the revocation status of the certificates of an issuer for OCSPResponder.
+type RevocationStore interface {
+	// Revocation returns the revocation entry of the serial number, or nil if it is not revoked.
+	// It returns an error that wraps ErrX509CertificateNotFound if the serial number is not known.
+	Revocation(serialNumber *big.Int) (*x509.RevocationListEntry, error)
+}
+
+// MemoryRevocationStore is RevocationStore in memory.
+type MemoryRevocationStore struct {
+	issued  CAStore
+	mu      sync.RWMutex
+	revoked map[string]x509.RevocationListEntry
+}
+
+// NewMemoryRevocationStore returns an empty *MemoryRevocationStore.
+// If issued is not nil, only the serial numbers saved in it are known, such as CA.Store; otherwise, every serial number is known.
+func (x509Utility) NewMemoryRevocationStore(issued CAStore) *MemoryRevocationStore {
+	return &MemoryRevocationStore{issued: issued, revoked: make(map[string]x509.RevocationListEntry)}
+}
+
+// Revoke records the revocation of the serial number with the reason code, such as X509RevocationReasonKeyCompromise.
+func (s *MemoryRevocationStore) Revoke(serialNumber *big.Int, reasonCode int, revocationTime time.Time) error {
+	if s.issued != nil {
+		if _, err := s.issued.Load(serialNumber); err != nil {
+			return fmt.Errorf("CAStore.Load: %w", err)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.revoked[serialNumber.String()] = x509.RevocationListEntry{SerialNumber: serialNumber, RevocationTime: revocationTime, ReasonCode: reasonCode}
+
+	return nil
+}
+
+// Revocation implements RevocationStore.
+func (s *MemoryRevocationStore) Revocation(serialNumber *big.Int) (*x509.RevocationListEntry, error) {
+	s.mu.RLock()
+	entry, ok := s.revoked[serialNumber.String()]
+	s.mu.RUnlock()
+
+	if ok {
+		return &entry, nil
+	}
+
+	if s.issued != nil {
+		if _, err := s.issued.Load(serialNumber); err != nil {
+			return nil, fmt.Errorf("CAStore.Load: %w", err)
+		}
+	}
+
+	return nil, nil // nolint: nilnil
+}
+
+// Entries returns the revocation entries, which can be passed to CA.CreateCRL.
+func (s *MemoryRevocationStore) Entries() []x509.RevocationListEntry {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entries := make([]x509.RevocationListEntry, 0, len(s.revoked))
+	for _, entry := range s.revoked {
+		entries = append(entries, entry)
+	}
+
+	slices.SortFunc(entries, func(a, b x509.RevocationListEntry) int { return a.SerialNumber.Cmp(b.SerialNumber) })
+
+	return entries
+}
+
+// OCSPResponder is an http.Handler that answers OCSP requests for the certificates of an issuer from RevocationStore.
+// It accepts both GET and POST requests defined in RFC 6960 Appendix A.
+type OCSPResponder struct {
+	issuer        *x509.Certificate
+	responderCert *x509.Certificate
+	responderKey  crypto.PrivateKey
+	store         RevocationStore
+	validity      time.Duration
+	now           func() time.Time
+	pathPrefix    string
+}
+
+// OCSPResponderOption is an option for X509.NewOCSPResponder.
+type OCSPResponderOption func(*OCSPResponder)
+
+// WithOCSPResponderValidity sets the length from ThisUpdate to NextUpdate of the responses. The default is X509DefaultOCSPResponseValidity.
+func (x509Utility) WithOCSPResponderValidity(validity time.Duration) OCSPResponderOption {
+	return func(r *OCSPResponder) { r.validity = validity }
+}
+
+// WithOCSPResponderClock replaces time.Now used for ThisUpdate.
+func (x509Utility) WithOCSPResponderClock(now func() time.Time) OCSPResponderOption {
+	return func(r *OCSPResponder) { r.now = now }
+}
+
+// WithOCSPResponderPathPrefix sets the path at which the responder is mounted, such as "/ocsp".
+// The rest of the path of a GET request is the request. The default is "/".
+func (x509Utility) WithOCSPResponderPathPrefix(prefix string) OCSPResponderOption {
+	return func(r *OCSPResponder) { r.pathPrefix = prefix }
+}
+
+// NewOCSPResponder returns *OCSPResponder that signs the responses with the responder certificate and key,
+// which may be the issuer itself. See X509.CreateOCSPResponse for the requirements.
+// It returns an error that wraps ErrCryptoUnsupportedKeyType if the key is not RSA or ECDSA,
+// or ErrX509PrivateKeyDoesNotMatch if the key does not match the responder certificate.
+func (x509Utility) NewOCSPResponder(issuer, responderCert *x509.Certificate, responderKey crypto.PrivateKey, store RevocationStore, opts ...OCSPResponderOption) (*OCSPResponder, error) {
+	signer, ok := responderKey.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("%T: %w", responderKey, ErrCryptoUnsupportedKeyType)
+	}
+
+	// NOTE: ocsp.CreateResponse signs only with RSA and ECDSA keys.
+	switch publicKey := signer.Public().(type) {
+	case *rsa.PublicKey, *ecdsa.PublicKey:
+		if !publicKey.(interface{ Equal(crypto.PublicKey) bool }).Equal(responderCert.PublicKey) {
+			return nil, fmt.Errorf("subject=%s: %w", responderCert.Subject, ErrX509PrivateKeyDoesNotMatch)
+		}
+	default:
+		return nil, fmt.Errorf("%T: OCSP responses are signed only with RSA or ECDSA keys: %w", publicKey, ErrCryptoUnsupportedKeyType)
+	}
+
+	r := &OCSPResponder{
+		issuer:        issuer,
+		responderCert: responderCert,
+		responderKey:  responderKey,
+		store:         store,
+		validity:      X509DefaultOCSPResponseValidity,
+		now:           time.Now,
+		pathPrefix:    "/",
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r, nil
+}
+
+// NewOCSPResponder returns *OCSPResponder that signs the responses with the CA certificate and key.
+func (ca *CA) NewOCSPResponder(store RevocationStore, opts ...OCSPResponderOption) (*OCSPResponder, error) {
+	return X509.NewOCSPResponder(ca.cert, ca.cert, ca.signer, store, append([]OCSPResponderOption{X509.WithOCSPResponderClock(ca.now)}, opts...)...)
+}
+
+// Respond returns the DER-encoded OCSP response to the DER-encoded OCSP request.
+// It returns an error only if it fails to sign the response; the problems of the request are answered by OCSP error responses.
+func (r *OCSPResponder) Respond(requestDER []byte) ([]byte, error) {
+	req, err := X509.ParseOCSPRequest(requestDER)
+	if err != nil {
+		return ocsp.MalformedRequestErrorResponse, nil
+	}
+
+	if err := r.checkIssuer(req); err != nil {
+		return ocsp.UnauthorizedErrorResponse, nil
+	}
+
+	template := ocsp.Response{Status: ocsp.Good, SerialNumber: req.SerialNumber, IssuerHash: req.HashAlgorithm}
+
+	entry, err := r.store.Revocation(req.SerialNumber)
+	switch {
+	case errors.Is(err, ErrX509CertificateNotFound):
+		template.Status = ocsp.Unknown
+	case err != nil:
+		return ocsp.InternalErrorErrorResponse, nil
+	case entry != nil:
+		template.Status = ocsp.Revoked
+		template.RevokedAt = entry.RevocationTime
+		template.RevocationReason = entry.ReasonCode
+	}
+
+	template.ThisUpdate = r.now()
+	template.NextUpdate = template.ThisUpdate.Add(r.validity)
+
+	return X509.CreateOCSPResponse(r.issuer, r.responderCert, r.responderKey, template)
+}
+
+func (r *OCSPResponder) checkIssuer(req *ocsp.Request) error {
+	var publicKeyInfo struct {
+		Algorithm pkix.AlgorithmIdentifier
+		PublicKey asn1.BitString
+	}
+
+	if _, err := asn1.Unmarshal(r.issuer.RawSubjectPublicKeyInfo, &publicKeyInfo); err != nil {
+		return fmt.Errorf("asn1.Unmarshal: %w", err)
+	}
+
+	if !req.HashAlgorithm.Available() {
+		return fmt.Errorf("hash=%v: %w", req.HashAlgorithm, ErrX509OCSPRequestIsNotForIssuer)
+	}
+
+	keyHash := req.HashAlgorithm.New()
+	keyHash.Write(publicKeyInfo.PublicKey.RightAlign())
+
+	nameHash := req.HashAlgorithm.New()
+	nameHash.Write(r.issuer.RawSubject)
+
+	if !bytes.Equal(keyHash.Sum(nil), req.IssuerKeyHash) || !bytes.Equal(nameHash.Sum(nil), req.IssuerNameHash) {
+		return ErrX509OCSPRequestIsNotForIssuer // nolint: wrapcheck
+	}
+
+	return nil
+}
+
+// ServeHTTP implements http.Handler.
+func (r *OCSPResponder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	var (
+		requestDER []byte
+		err        error
+	)
+
+	switch req.Method {
+	case http.MethodGet:
+		// The request is the whole rest of the path, since clients often leave "/" in base64 unescaped.
+		// A path without the prefix leaves requestDER empty, which is answered as malformed.
+		if rest, ok := strings.CutPrefix(req.URL.EscapedPath(), r.pathPrefix); ok {
+			var encoded string
+
+			encoded, err = url.PathUnescape(strings.TrimLeft(rest, "/"))
+			if err == nil {
+				requestDER, err = base64.StdEncoding.DecodeString(encoded)
+			}
+		}
+	case http.MethodPost:
+		requestDER, err = io.ReadAll(io.LimitReader(req.Body, x509OCSPMaxRequestSize))
+	default:
+		w.Header().Set("Allow", http.MethodGet+", "+http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	responseDER := ocsp.MalformedRequestErrorResponse
+	if err == nil {
+		if responseDER, err = r.Respond(requestDER); err != nil {
+			responseDER = ocsp.InternalErrorErrorResponse
+		}
+	}
+
+	w.Header().Set("Content-Type", x509OCSPResponseContentType)
+	_, _ = w.Write(responseDER)
+}
+
+// LocalHTTPClient returns *http.Client that sends every request to the responder in process without network,
+// which can be passed to X509.WithOCSPCheckerHTTPClient in tests.
+func (r *OCSPResponder) LocalHTTPClient() *http.Client {
+	return &http.Client{Transport: x509LocalRoundTripper{handler: r}}
+}
+
+type x509LocalRoundTripper struct {
+	handler http.Handler
+}
+
+func (t x509LocalRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		defer req.Body.Close()
+	}
+
+	w := &x509LocalResponseWriter{header: make(http.Header)}
+	t.handler.ServeHTTP(w, req)
+	w.WriteHeader(http.StatusOK)
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", w.code, http.StatusText(w.code)),
+		StatusCode:    w.code,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        w.header,
+		Body:          io.NopCloser(&w.body),
+		ContentLength: int64(w.body.Len()),
+		Request:       req,
+	}, nil
+}
+
+// x509LocalResponseWriter is http.ResponseWriter that buffers the response for x509LocalRoundTripper.
+type x509LocalResponseWriter struct {
+	header http.Header
+	code   int
+	body   bytes.Buffer
+}
+
+func (w *x509LocalResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *x509LocalResponseWriter) WriteHeader(code int) {
+	if w.code == 0 {
+		w.code = code
+	}
+}
+
+func (w *x509LocalResponseWriter) Write(p []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+
+	return w.body.Write(p) // nolint: wrapcheck
+}
+
+// OCSPChecker checks the revocation status of certificates by querying the OCSP servers in their authority information access,
+// and caches the verified responses until their NextUpdate.
+type OCSPChecker struct {
+	client *http.Client
+	now    func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]*OCSPStatus
+}
+
+// OCSPCheckerOption is an option for X509.NewOCSPChecker.
+type OCSPCheckerOption func(*OCSPChecker)
+
+// WithOCSPCheckerHTTPClient replaces http.DefaultClient used to query the OCSP servers.
+func (x509Utility) WithOCSPCheckerHTTPClient(client *http.Client) OCSPCheckerOption {
+	return func(c *OCSPChecker) { c.client = client }
+}
+
+// WithOCSPCheckerClock replaces time.Now used to expire the cache, and to check that the responses and their delegated responders are current.
+func (x509Utility) WithOCSPCheckerClock(now func() time.Time) OCSPCheckerOption {
+	return func(c *OCSPChecker) { c.now = now }
+}
+
+// NewOCSPChecker returns *OCSPChecker with an empty cache.
+func (x509Utility) NewOCSPChecker(opts ...OCSPCheckerOption) *OCSPChecker {
+	c := &OCSPChecker{client: http.DefaultClient, now: time.Now, cache: make(map[string]*OCSPStatus)}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// Check returns the status of the certificate issued by the issuer, from the cache or the OCSP servers in cert.OCSPServer in order.
+// It returns an error that wraps ErrX509CertificateHasNoOCSPServer if the certificate has no OCSP server,
+// or the error of the last server if none of them answers a verified response.
+func (c *OCSPChecker) Check(ctx context.Context, cert, issuer *x509.Certificate) (*OCSPStatus, error) {
+	if len(cert.OCSPServer) == 0 {
+		return nil, fmt.Errorf("subject=%s: %w", cert.Subject, ErrX509CertificateHasNoOCSPServer)
+	}
+
+	key := string(issuer.RawSubjectPublicKeyInfo) + cert.SerialNumber.String()
+
+	c.mu.Lock()
+	status, ok := c.cache[key]
+	if ok && !c.now().Before(status.NextUpdate) {
+		delete(c.cache, key)
+
+		ok = false
+	}
+	c.mu.Unlock()
+
+	if ok {
+		return status, nil
+	}
+
+	requestDER, err := X509.CreateOCSPRequest(cert, issuer)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, server := range cert.OCSPServer {
+		status, err = c.query(ctx, server, requestDER, cert, issuer)
+		if err != nil {
+			continue
+		}
+
+		if !status.NextUpdate.IsZero() {
+			c.mu.Lock()
+			c.cache[key] = status
+			c.mu.Unlock()
+		}
+
+		return status, nil
+	}
+
+	return nil, err
+}
+
+func (c *OCSPChecker) query(ctx context.Context, server string, requestDER []byte, cert, issuer *x509.Certificate) (*OCSPStatus, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, server, bytes.NewReader(requestDER))
+	if err != nil {
+		return nil, fmt.Errorf("http.NewRequestWithContext: %w", err)
+	}
+
+	req.Header.Set("Content-Type", x509OCSPRequestContentType)
+	req.Header.Set("Accept", x509OCSPResponseContentType)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("(*http.Client).Do: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("server=%s status=%d: %w", server, resp.StatusCode, ErrX509OCSPServerFailed)
+	}
+
+	responseDER, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("io.ReadAll: %w", err)
+	}
+
+	return X509.parseOCSPResponse(responseDER, cert, issuer, c.now())
+}
+
+// WithOCSPServers adds the URLs of the OCSP servers to the authority information access.
+func (x509Utility) WithOCSPServers(servers ...string) X509CertificateOption {
+	return func(o *x509CertificateOptions) { o.template.OCSPServer = append(o.template.OCSPServer, servers...) }
+}
+
+// WithCRLDistributionPoints adds the URLs of the CRL distribution points.
+func (x509Utility) WithCRLDistributionPoints(urls ...string) X509CertificateOption {
+	return func(o *x509CertificateOptions) {
+		o.template.CRLDistributionPoints = append(o.template.CRLDistributionPoints, urls...)
+	}
+}
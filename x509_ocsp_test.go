@@ -0,0 +1,434 @@
+// nolint: testpackage
+package nits
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ocsp"
+)
+
+func TestOCSPResponder(t *testing.T) {
+	t.Parallel()
+
+	ca := testNewRootCA(t)
+	store := X509.NewMemoryRevocationStore(ca.Store())
+
+	goodCert, _, err := ca.Issue(testGeneratePublicKey(t), X509.WithOCSPServers("http://ocsp.example.com"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	revokedCert, _, err := ca.Issue(testGeneratePublicKey(t), X509.WithOCSPServers("http://ocsp.example.com"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	revocationTime := time.Now().Add(-time.Hour).Truncate(time.Second)
+	if err := store.Revoke(revokedCert.SerialNumber, X509RevocationReasonSuperseded, revocationTime); err != nil {
+		t.Fatal(err)
+	}
+
+	responder, err := ca.NewOCSPResponder(store, X509.WithOCSPResponderValidity(time.Hour), X509.WithOCSPResponderPathPrefix("/ocsp"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("success(Respond)", func(t *testing.T) {
+		t.Parallel()
+		for cert, expect := range map[*x509.Certificate]OCSPCertStatus{goodCert: OCSPCertStatusGood, revokedCert: OCSPCertStatusRevoked} {
+			requestDER, err := X509.CreateOCSPRequest(cert, ca.Certificate())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if req, err := X509.ParseOCSPRequest(requestDER); err != nil || req.SerialNumber.Cmp(cert.SerialNumber) != 0 {
+				t.Fatalf("unexpected request: %v", err)
+			}
+
+			responseDER, err := responder.Respond(requestDER)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			status, err := X509.ParseOCSPResponse(responseDER, cert, ca.Certificate())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if status.Status != expect || status.NextUpdate.Sub(status.ThisUpdate) != time.Hour {
+				t.Errorf("unexpected status: %+v", status)
+			}
+
+			if expect == OCSPCertStatusRevoked && (!status.RevocationTime.Equal(revocationTime) || status.Reason != "superseded") {
+				t.Errorf("unexpected revocation: %+v", status)
+			}
+		}
+	})
+
+	t.Run("success(Unknown)", func(t *testing.T) {
+		t.Parallel()
+		requestDER, err := X509.CreateOCSPRequest(goodCert, ca.Certificate())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		responder, err := ca.NewOCSPResponder(X509.NewMemoryRevocationStore(X509.NewMemoryCAStore()))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		responseDER, err := responder.Respond(requestDER)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if status, err := X509.ParseOCSPResponse(responseDER, goodCert, ca.Certificate()); err != nil || status.Status != OCSPCertStatusUnknown {
+			t.Errorf("unexpected status: %+v: %v", status, err)
+		}
+	})
+
+	t.Run("success(DelegatedResponder)", func(t *testing.T) {
+		t.Parallel()
+		responderKey, err := Crypto.GenerateKey(CryptoECDSA256)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		responderCert, _, err := ca.Issue(responderKey.(crypto.Signer).Public(), X509.WithExtKeyUsage(x509.ExtKeyUsageOCSPSigning))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		requestDER, err := X509.CreateOCSPRequest(revokedCert, ca.Certificate())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		responder, err := X509.NewOCSPResponder(ca.Certificate(), responderCert, responderKey, store)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		responseDER, err := responder.Respond(requestDER)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if status, err := X509.ParseOCSPResponse(responseDER, revokedCert, ca.Certificate()); err != nil || status.Status != OCSPCertStatusRevoked {
+			t.Errorf("unexpected status: %+v: %v", status, err)
+		}
+	})
+
+	t.Run("failure(DelegatedResponder)", func(t *testing.T) {
+		t.Parallel()
+		responderKey, err := Crypto.GenerateKey(CryptoECDSA256)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		requestDER, err := X509.CreateOCSPRequest(revokedCert, ca.Certificate())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		clientCert, _, err := ca.IssueClientCertificate(responderKey.(crypto.Signer).Public())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		responder, err := X509.NewOCSPResponder(ca.Certificate(), clientCert, responderKey, store)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		responseDER, err := responder.Respond(requestDER)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := X509.ParseOCSPResponse(responseDER, revokedCert, ca.Certificate()); !errors.Is(err, ErrX509OCSPResponderIsNotAuthorized) {
+			t.Errorf("err != ErrX509OCSPResponderIsNotAuthorized: %v", err)
+		}
+
+		responderCert, _, err := ca.Issue(responderKey.(crypto.Signer).Public(), X509.WithExtKeyUsage(x509.ExtKeyUsageOCSPSigning), X509.WithValidity(time.Hour))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		responder, err = X509.NewOCSPResponder(ca.Certificate(), responderCert, responderKey, store)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		responseDER, err = responder.Respond(requestDER)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := X509.parseOCSPResponse(responseDER, revokedCert, ca.Certificate(), responderCert.NotAfter.Add(time.Second)); !errors.Is(err, ErrX509CertificateHasExpired) {
+			t.Errorf("err != ErrX509CertificateHasExpired: %v", err)
+		}
+
+		if _, err := X509.parseOCSPResponse(responseDER, revokedCert, ca.Certificate(), responderCert.NotBefore.Add(-time.Second)); !errors.Is(err, ErrX509CertificateIsNotYetValid) {
+			t.Errorf("err != ErrX509CertificateIsNotYetValid: %v", err)
+		}
+	})
+
+	t.Run("failure(ErrX509OCSPResponseIsNotCurrent)", func(t *testing.T) {
+		t.Parallel()
+		requestDER, err := X509.CreateOCSPRequest(goodCert, ca.Certificate())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for name, thisUpdate := range map[string]time.Time{"replayed": time.Now().Add(-2 * time.Hour), "future": time.Now().Add(time.Hour)} {
+			thisUpdate := thisUpdate
+
+			responder, err := ca.NewOCSPResponder(store, X509.WithOCSPResponderValidity(time.Hour), X509.WithOCSPResponderClock(func() time.Time { return thisUpdate }))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			responseDER, err := responder.Respond(requestDER)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := X509.ParseOCSPResponse(responseDER, goodCert, ca.Certificate()); !errors.Is(err, ErrX509OCSPResponseIsNotCurrent) {
+				t.Errorf("%s: err != ErrX509OCSPResponseIsNotCurrent: %v", name, err)
+			}
+		}
+	})
+
+	t.Run("success(ServeHTTP,GET)", func(t *testing.T) {
+		t.Parallel()
+		requestDER, err := X509.CreateOCSPRequest(goodCert, ca.Certificate())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rec := httptest.NewRecorder()
+		responder.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ocsp/"+url.PathEscape(base64.StdEncoding.EncodeToString(requestDER)), nil))
+
+		if rec.Header().Get("Content-Type") != "application/ocsp-response" {
+			t.Errorf("unexpected Content-Type: %s", rec.Header().Get("Content-Type"))
+		}
+
+		if status, err := X509.ParseOCSPResponse(rec.Body.Bytes(), goodCert, ca.Certificate()); err != nil || status.Status != OCSPCertStatusGood {
+			t.Errorf("unexpected status: %+v: %v", status, err)
+		}
+
+		// Issue until the base64 of the request contains "/", which clients often leave unescaped.
+		var cert *x509.Certificate
+		var encoded string
+		for !strings.Contains(encoded, "/") {
+			if cert, _, err = ca.Issue(testGeneratePublicKey(t)); err != nil {
+				t.Fatal(err)
+			}
+
+			if requestDER, err = X509.CreateOCSPRequest(cert, ca.Certificate()); err != nil {
+				t.Fatal(err)
+			}
+
+			encoded = base64.StdEncoding.EncodeToString(requestDER)
+		}
+
+		rec = httptest.NewRecorder()
+		responder.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ocsp/"+encoded, nil))
+
+		if status, err := X509.ParseOCSPResponse(rec.Body.Bytes(), cert, ca.Certificate()); err != nil || status.Status != OCSPCertStatusGood {
+			t.Errorf("unexpected status: %+v: %v", status, err)
+		}
+	})
+
+	t.Run("failure(ServeHTTP)", func(t *testing.T) {
+		t.Parallel()
+		requestDER, err := X509.CreateOCSPRequest(goodCert, ca.Certificate())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rec := httptest.NewRecorder()
+		responder.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/other/"+url.PathEscape(base64.StdEncoding.EncodeToString(requestDER)), nil))
+
+		if !bytes.Equal(rec.Body.Bytes(), ocsp.MalformedRequestErrorResponse) {
+			t.Errorf("unexpected response: %x", rec.Body.Bytes())
+		}
+
+		rec = httptest.NewRecorder()
+		responder.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", bytes.NewReader([]byte("malformed"))))
+
+		if !bytes.Equal(rec.Body.Bytes(), ocsp.MalformedRequestErrorResponse) {
+			t.Errorf("unexpected response: %x", rec.Body.Bytes())
+		}
+
+		other := testNewRootCA(t)
+		otherCert, _, err := other.Issue(testGeneratePublicKey(t))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		requestDER, err = X509.CreateOCSPRequest(otherCert, other.Certificate())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rec = httptest.NewRecorder()
+		responder.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(requestDER)))
+
+		if !bytes.Equal(rec.Body.Bytes(), ocsp.UnauthorizedErrorResponse) {
+			t.Errorf("unexpected response: %x", rec.Body.Bytes())
+		}
+
+		rec = httptest.NewRecorder()
+		responder.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/", nil))
+
+		if rec.Code != http.StatusMethodNotAllowed {
+			t.Errorf("unexpected status: %d", rec.Code)
+		}
+	})
+
+	t.Run("failure(NewOCSPResponder)", func(t *testing.T) {
+		t.Parallel()
+		ed25519Key, err := Crypto.GenerateKey(CryptoEd25519)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := X509.NewOCSPResponder(ca.Certificate(), ca.Certificate(), ed25519Key, store); !errors.Is(err, ErrCryptoUnsupportedKeyType) {
+			t.Errorf("err != ErrCryptoUnsupportedKeyType: %v", err)
+		}
+
+		otherKey, err := Crypto.GenerateKey(CryptoECDSA256)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := X509.NewOCSPResponder(ca.Certificate(), ca.Certificate(), otherKey, store); !errors.Is(err, ErrX509PrivateKeyDoesNotMatch) {
+			t.Errorf("err != ErrX509PrivateKeyDoesNotMatch: %v", err)
+		}
+	})
+
+	t.Run("success(CRL)", func(t *testing.T) {
+		t.Parallel()
+		crl, _, err := ca.CreateCRL(store.Entries())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if status, err := X509.CheckRevocation(revokedCert, ca.Certificate(), crl); err != nil || !status.Revoked {
+			t.Errorf("unexpected status: %+v: %v", status, err)
+		}
+	})
+
+	t.Run("failure(Revoke)", func(t *testing.T) {
+		t.Parallel()
+		otherCert, _, err := testNewRootCA(t).Issue(testGeneratePublicKey(t))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := store.Revoke(otherCert.SerialNumber, X509RevocationReasonUnspecified, time.Now()); !errors.Is(err, ErrX509CertificateNotFound) {
+			t.Errorf("err != ErrX509CertificateNotFound: %v", err)
+		}
+	})
+}
+
+// NOTE: The subtests advance the clock shared by the responder and the checker, so they run in order.
+//
+// nolint: paralleltest
+func TestOCSPChecker_Check(t *testing.T) {
+	ca := testNewRootCA(t)
+	store := X509.NewMemoryRevocationStore(nil)
+	now := time.Now()
+	clock := func() time.Time { return now }
+	responder, err := ca.NewOCSPResponder(store, X509.WithOCSPResponderValidity(time.Hour), X509.WithOCSPResponderClock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cert, _, err := ca.Issue(testGeneratePublicKey(t), X509.WithOCSPServers("http://unreachable.invalid/ocsp", "http://ocsp.example.com"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var requests int32
+
+	checker := X509.NewOCSPChecker(
+		X509.WithOCSPCheckerHTTPClient(&http.Client{Transport: x509LocalRoundTripper{handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Host == "unreachable.invalid" {
+				http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+
+				return
+			}
+
+			atomic.AddInt32(&requests, 1)
+			responder.ServeHTTP(w, r)
+		})}}),
+		X509.WithOCSPCheckerClock(clock),
+	)
+
+	t.Run("success(Cache)", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			status, err := checker.Check(context.Background(), cert, ca.Certificate())
+			if err != nil || status.Status != OCSPCertStatusGood {
+				t.Fatalf("unexpected status: %+v: %v", status, err)
+			}
+		}
+
+		if requests != 1 {
+			t.Errorf("requests != 1: %d", requests)
+		}
+
+		if err := store.Revoke(cert.SerialNumber, X509RevocationReasonKeyCompromise, now); err != nil {
+			t.Fatal(err)
+		}
+
+		now = now.Add(2 * time.Hour)
+
+		status, err := checker.Check(context.Background(), cert, ca.Certificate())
+		if err != nil || status.Status != OCSPCertStatusRevoked || status.ReasonCode != X509RevocationReasonKeyCompromise {
+			t.Errorf("unexpected status: %+v: %v", status, err)
+		}
+
+		if requests != 2 {
+			t.Errorf("requests != 2: %d", requests)
+		}
+	})
+
+	t.Run("success(LocalHTTPClient)", func(t *testing.T) {
+		status, err := X509.NewOCSPChecker(X509.WithOCSPCheckerHTTPClient(responder.LocalHTTPClient()), X509.WithOCSPCheckerClock(clock)).Check(context.Background(), cert, ca.Certificate())
+		if err != nil || status.Status != OCSPCertStatusRevoked {
+			t.Errorf("unexpected status: %+v: %v", status, err)
+		}
+	})
+
+	t.Run("failure(ErrX509CertificateHasNoOCSPServer)", func(t *testing.T) {
+		if _, err := checker.Check(context.Background(), ca.Certificate(), ca.Certificate()); !errors.Is(err, ErrX509CertificateHasNoOCSPServer) {
+			t.Errorf("err != ErrX509CertificateHasNoOCSPServer: %v", err)
+		}
+	})
+
+	t.Run("failure(ErrX509OCSPServerFailed)", func(t *testing.T) {
+		failing, _, err := ca.Issue(testGeneratePublicKey(t), X509.WithOCSPServers("http://unreachable.invalid/ocsp"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := checker.Check(context.Background(), failing, ca.Certificate()); !errors.Is(err, ErrX509OCSPServerFailed) {
+			t.Errorf("err != ErrX509OCSPServerFailed: %v", err)
+		}
+	})
+}
@@ -0,0 +1,127 @@
+package nits
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+)
+
+// ErrX509UnsupportedPEMBlockType unsupported PEM block type.
+var ErrX509UnsupportedPEMBlockType = errors.New("unsupported PEM block type")
+
+const (
+	x509PEMTypePrivateKey            = "PRIVATE KEY"
+	x509PEMTypeRSAPrivateKey         = "RSA PRIVATE KEY"
+	x509PEMTypeECPrivateKey          = "EC PRIVATE KEY"
+	x509PEMTypePublicKey             = "PUBLIC KEY"
+	x509PEMTypeRSAPublicKey          = "RSA PUBLIC KEY"
+	x509PEMTypeNewCertificateRequest = "NEW CERTIFICATE REQUEST"
+	x509PEMTypeECParameters          = "EC PARAMETERS"
+	x509PEMTypeEncryptedPrivateKey   = "ENCRYPTED PRIVATE KEY"
+)
+
+// PEMObject is an object parsed from a PEM block by X509.ParsePEM.
+type PEMObject struct {
+	// Type is the type of the PEM block such as "EC PRIVATE KEY".
+	Type    string
+	Headers map[string]string
+	// Value is crypto.PrivateKey, crypto.PublicKey, *x509.Certificate, *x509.CertificateRequest or *x509.RevocationList.
+	Value interface{}
+}
+
+// ParsePEM returns the objects in all blocks of the passed PEM data in order. The supported block types are
+// "PRIVATE KEY" (PKCS #8), "RSA PRIVATE KEY" (PKCS #1), "EC PRIVATE KEY" (SEC 1), "PUBLIC KEY" (PKIX), "RSA PUBLIC KEY" (PKCS #1),
+// "CERTIFICATE", "CERTIFICATE REQUEST", "NEW CERTIFICATE REQUEST" and "X509 CRL".
+// Private key blocks are parsed in any of the encodings, as X509.ParsePKCSXPrivateKeyPEM does.
+// "EC PARAMETERS" blocks written by `openssl ecparam -genkey` are skipped since SEC 1 keys contain the curve,
+// and any other block type results in ErrX509UnsupportedPEMBlockType.
+func (x509Utility) ParsePEM(pemData []byte) ([]PEMObject, error) {
+	var objects []PEMObject
+
+	for i := 0; ; i++ {
+		var block *pem.Block
+
+		block, pemData = pem.Decode(pemData)
+		if block == nil {
+			break
+		}
+
+		if block.Type == x509PEMTypeECParameters {
+			continue
+		}
+
+		value, err := X509.parsePEMBlock(block)
+		if err != nil {
+			return nil, fmt.Errorf("index=%d type=%s: %w", i, block.Type, err)
+		}
+
+		objects = append(objects, PEMObject{Type: block.Type, Headers: block.Headers, Value: value})
+	}
+
+	if len(objects) == 0 {
+		return nil, ErrX509InvalidPEMFormat // nolint: wrapcheck
+	}
+
+	return objects, nil
+}
+
+func (x509Utility) parsePEMBlock(block *pem.Block) (interface{}, error) {
+	var (
+		value interface{}
+		err   error
+	)
+
+	switch block.Type {
+	case x509PEMTypePrivateKey, x509PEMTypeRSAPrivateKey, x509PEMTypeECPrivateKey:
+		return x509ParsePrivateKeyDER(block.Bytes)
+	case x509PEMTypePublicKey:
+		value, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case x509PEMTypeRSAPublicKey:
+		value, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	case x509PEMTypeCertificate:
+		value, err = x509.ParseCertificate(block.Bytes)
+	case x509PEMTypeCertificateRequest, x509PEMTypeNewCertificateRequest:
+		value, err = x509.ParseCertificateRequest(block.Bytes)
+	case x509PEMTypeCRL:
+		value, err = x509.ParseRevocationList(block.Bytes)
+	case x509PEMTypeEncryptedPrivateKey:
+		return nil, fmt.Errorf("encrypted private keys are not supported: %w", ErrX509UnsupportedPEMBlockType)
+	default:
+		return nil, ErrX509UnsupportedPEMBlockType // nolint: wrapcheck
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", err, ErrX509InvalidPEMFormat)
+	}
+
+	return value, nil
+}
+
+// MarshalPKIXPublicKeyPEM returns the public key as a "PUBLIC KEY" PEM block.
+func (x509Utility) MarshalPKIXPublicKeyPEM(publicKey crypto.PublicKey) (pemData []byte, err error) {
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("x509.MarshalPKIXPublicKey: %w", err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: x509PEMTypePublicKey, Bytes: der}), nil
+}
+
+// MarshalPKCS1PublicKeyPEM returns the RSA public key as a "RSA PUBLIC KEY" PEM block.
+func (x509Utility) MarshalPKCS1PublicKeyPEM(publicKey *rsa.PublicKey) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: x509PEMTypeRSAPublicKey, Bytes: x509.MarshalPKCS1PublicKey(publicKey)})
+}
+
+// MarshalSEC1PrivateKeyPEM returns the ECDSA private key as a "EC PRIVATE KEY" PEM block, which is the format of `openssl ecparam -genkey`.
+func (x509Utility) MarshalSEC1PrivateKeyPEM(privateKey *ecdsa.PrivateKey) (pemData []byte, err error) {
+	der, err := x509.MarshalECPrivateKey(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("x509.MarshalECPrivateKey: %w", err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: x509PEMTypeECPrivateKey, Bytes: der}), nil
+}
@@ -0,0 +1,108 @@
+package nits
+
+import (
+	"crypto/x509"
+	"fmt"
+	"time"
+)
+
+// CertificateState is the state of CertificateStatus.
+type CertificateState = string
+
+const (
+	// CertificateStateNotYetValid means that the current time is before NotBefore.
+	CertificateStateNotYetValid CertificateState = "not_yet_valid"
+	// CertificateStateValid means that the certificate is valid and does not expire within the warning threshold.
+	CertificateStateValid CertificateState = "valid"
+	// CertificateStateExpiringSoon means that the certificate is valid but expires within the warning threshold.
+	CertificateStateExpiringSoon CertificateState = "expiring_soon"
+	// CertificateStateExpired means that the current time is after NotAfter.
+	CertificateStateExpired CertificateState = "expired"
+
+	// X509DefaultExpiryWarningThreshold is the default warning threshold of X509.CertificateStatus.
+	X509DefaultExpiryWarningThreshold = 30 * 24 * time.Hour
+)
+
+// CertificateStatus is the validity status of a certificate at CheckedAt.
+type CertificateStatus struct {
+	State     CertificateState `json:"state"`
+	CheckedAt time.Time        `json:"checkedAt"`
+	NotBefore time.Time        `json:"notBefore"`
+	NotAfter  time.Time        `json:"notAfter"`
+	// UntilValid is the duration until NotBefore, which is zero or negative once the certificate is valid.
+	UntilValid time.Duration `json:"untilValid"`
+	// UntilExpiry is the duration until NotAfter, which is negative once the certificate has expired.
+	UntilExpiry time.Duration `json:"untilExpiry"`
+}
+
+// Err returns an error that wraps ErrX509CertificateIsNotYetValid or ErrX509CertificateHasExpired, or nil if the certificate is valid.
+func (s *CertificateStatus) Err() error {
+	switch s.State {
+	case CertificateStateNotYetValid:
+		return fmt.Errorf("notBefore=%s: %w", s.NotBefore.Format(time.RFC3339), ErrX509CertificateIsNotYetValid)
+	case CertificateStateExpired:
+		return fmt.Errorf("notAfter=%s: %w", s.NotAfter.Format(time.RFC3339), ErrX509CertificateHasExpired)
+	default:
+		return nil
+	}
+}
+
+type certificateStatusOptions struct {
+	now              func() time.Time
+	warningThreshold time.Duration
+}
+
+// CertificateStatusOption is an option for X509.CertificateStatus.
+type CertificateStatusOption func(*certificateStatusOptions)
+
+// WithCertificateStatusClock replaces time.Now used as the current time.
+func (x509Utility) WithCertificateStatusClock(now func() time.Time) CertificateStatusOption {
+	return func(o *certificateStatusOptions) { o.now = now }
+}
+
+// WithCertificateStatusWarningThreshold sets the duration before NotAfter from which the state is CertificateStateExpiringSoon.
+// The default is X509DefaultExpiryWarningThreshold.
+func (x509Utility) WithCertificateStatusWarningThreshold(threshold time.Duration) CertificateStatusOption {
+	return func(o *certificateStatusOptions) { o.warningThreshold = threshold }
+}
+
+// CertificateStatus returns the validity status of the certificate.
+// If the certificate is not yet valid or has expired, it returns the status together with the error returned by CertificateStatus.Err.
+func (x509Utility) CertificateStatus(cert *x509.Certificate, opts ...CertificateStatusOption) (*CertificateStatus, error) {
+	o := &certificateStatusOptions{now: time.Now, warningThreshold: X509DefaultExpiryWarningThreshold}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	now := o.now()
+	status := &CertificateStatus{
+		CheckedAt:   now,
+		NotBefore:   cert.NotBefore,
+		NotAfter:    cert.NotAfter,
+		UntilValid:  cert.NotBefore.Sub(now),
+		UntilExpiry: cert.NotAfter.Sub(now),
+	}
+
+	switch {
+	case now.Before(cert.NotBefore):
+		status.State = CertificateStateNotYetValid
+	case now.After(cert.NotAfter):
+		status.State = CertificateStateExpired
+	case status.UntilExpiry <= o.warningThreshold:
+		status.State = CertificateStateExpiringSoon
+	default:
+		status.State = CertificateStateValid
+	}
+
+	return status, status.Err()
+}
+
+// CertificateStatusPEM returns the validity status of the certificate in the passed PEM data.
+func (x509Utility) CertificateStatusPEM(pemData []byte, opts ...CertificateStatusOption) (*CertificateStatus, error) {
+	cert, err := X509.ParseCertificatePEM(pemData)
+	if err != nil {
+		return nil, fmt.Errorf("X509.ParseCertificatePEM: %w", err)
+	}
+
+	return X509.CertificateStatus(cert, opts...)
+}
@@ -0,0 +1,98 @@
+// nolint: testpackage
+package nits
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestX509Utility_CertificateStatus(t *testing.T) {
+	t.Parallel()
+
+	privateKey, err := Crypto.GenerateKey(CryptoEd25519)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	notBefore := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	cert, _, err := X509.CreateSelfSigned(privateKey, X509.WithNotBefore(notBefore), X509.WithValidity(90*24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clock := func(t time.Time) CertificateStatusOption {
+		return X509.WithCertificateStatusClock(func() time.Time { return t })
+	}
+
+	tests := []struct {
+		name        string
+		opts        []CertificateStatusOption
+		state       CertificateState
+		untilExpiry time.Duration
+		wantErr     error
+	}{
+		{"success(Valid)", []CertificateStatusOption{clock(notBefore.Add(time.Hour))}, CertificateStateValid, 90*24*time.Hour - time.Hour, nil},
+		{"success(ExpiringSoon)", []CertificateStatusOption{clock(notBefore.Add(80 * 24 * time.Hour))}, CertificateStateExpiringSoon, 10 * 24 * time.Hour, nil},
+		{"success(Valid,WarningThreshold)", []CertificateStatusOption{clock(notBefore.Add(80 * 24 * time.Hour)), X509.WithCertificateStatusWarningThreshold(24 * time.Hour)}, CertificateStateValid, 10 * 24 * time.Hour, nil},
+		{"failure(ErrX509CertificateIsNotYetValid)", []CertificateStatusOption{clock(notBefore.Add(-time.Minute))}, CertificateStateNotYetValid, 90*24*time.Hour + time.Minute, ErrX509CertificateIsNotYetValid},
+		{"failure(ErrX509CertificateHasExpired)", []CertificateStatusOption{clock(notBefore.Add(91 * 24 * time.Hour))}, CertificateStateExpired, -24 * time.Hour, ErrX509CertificateHasExpired},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			status, err := X509.CertificateStatus(cert, tt.opts...)
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil) != (err == nil) {
+				t.Errorf("err != %v: %v", tt.wantErr, err)
+			}
+
+			if status.State != tt.state || status.UntilExpiry != tt.untilExpiry || !status.NotAfter.Equal(cert.NotAfter) {
+				t.Errorf("unexpected status: %+v", status)
+			}
+		})
+	}
+
+	t.Run("success(JSON)", func(t *testing.T) {
+		t.Parallel()
+		status, _ := X509.CertificateStatus(cert, clock(notBefore))
+
+		data, err := json.Marshal(status)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !strings.Contains(string(data), `"state":"valid"`) {
+			t.Errorf("unexpected JSON: %s", data)
+		}
+	})
+}
+
+func TestX509Utility_CertificateStatusPEM(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success()", func(t *testing.T) {
+		t.Parallel()
+		status, err := X509.CertificateStatusPEM([]byte(testCrtPEMString))
+		if err != nil || status.State != CertificateStateValid {
+			t.Errorf("unexpected status: %+v: %v", status, err)
+		}
+	})
+
+	t.Run("failure(ErrX509CertificateHasExpired)", func(t *testing.T) {
+		t.Parallel()
+		status, err := X509.CertificateStatusPEM([]byte(testCrtPEMExpiredString))
+		if !errors.Is(err, ErrX509CertificateHasExpired) || status.State != CertificateStateExpired {
+			t.Errorf("unexpected status: %+v: %v", status, err)
+		}
+	})
+
+	t.Run("failure(ErrX509InvalidPEMFormat)", func(t *testing.T) {
+		t.Parallel()
+		if _, err := X509.CertificateStatusPEM([]byte("invalid")); !errors.Is(err, ErrX509InvalidPEMFormat) {
+			t.Errorf("err != ErrX509InvalidPEMFormat: %v", err)
+		}
+	})
+}
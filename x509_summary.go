@@ -0,0 +1,356 @@
+package nits
+
+import (
+	"crypto/sha1" // nolint: gosec
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// CertificateExtension is an extension in CertificateSummary.
+type CertificateExtension struct {
+	OID string `json:"oid"`
+	// Name is the name of the extension such as "subjectAltName". It is empty for unknown extensions.
+	Name     string `json:"name,omitempty"`
+	Critical bool   `json:"critical"`
+}
+
+// CertificateSummary describes a certificate in the way of `openssl x509 -text`, so that it can be logged or printed when investigating.
+// Fingerprints, serial numbers and key identifiers are uppercase hex separated by colons.
+type CertificateSummary struct {
+	Subject            string `json:"subject"`
+	Issuer             string `json:"issuer"`
+	SerialNumber       string `json:"serialNumber"`
+	Version            int    `json:"version"`
+	SignatureAlgorithm string `json:"signatureAlgorithm"`
+	// PublicKey is nil if the key type is not supported by Crypto.KeyInfo.
+	PublicKey *KeyInfo `json:"publicKey"`
+	IsCA      bool     `json:"isCA"`
+	// MaxPathLen is -1 if the path length is unlimited or the certificate is not a CA.
+	MaxPathLen     int      `json:"maxPathLen"`
+	DNSNames       []string `json:"dnsNames,omitempty"`
+	IPAddresses    []string `json:"ipAddresses,omitempty"`
+	URIs           []string `json:"uris,omitempty"`
+	EmailAddresses []string `json:"emailAddresses,omitempty"`
+	// KeyUsages are the names defined in RFC 5280 4.2.1.3 such as "digitalSignature".
+	KeyUsages []string `json:"keyUsages,omitempty"`
+	// ExtKeyUsages are the names such as "serverAuth", or the OIDs for unknown usages.
+	ExtKeyUsages          []string               `json:"extKeyUsages,omitempty"`
+	NotBefore             time.Time              `json:"notBefore"`
+	NotAfter              time.Time              `json:"notAfter"`
+	Status                *CertificateStatus     `json:"status"`
+	SubjectKeyID          string                 `json:"subjectKeyId,omitempty"`
+	AuthorityKeyID        string                 `json:"authorityKeyId,omitempty"`
+	OCSPServers           []string               `json:"ocspServers,omitempty"`
+	IssuingCertificateURL []string               `json:"issuingCertificateURL,omitempty"`
+	CRLDistributionPoints []string               `json:"crlDistributionPoints,omitempty"`
+	FingerprintSHA256     string                 `json:"fingerprintSHA256"`
+	FingerprintSHA1       string                 `json:"fingerprintSHA1"`
+	Extensions            []CertificateExtension `json:"extensions"`
+}
+
+// CertificateSummaries is the summaries of a chain from the leaf.
+type CertificateSummaries []*CertificateSummary
+
+// nolint: gochecknoglobals
+var (
+	x509KeyUsageNames = []string{
+		"digitalSignature",
+		"contentCommitment",
+		"keyEncipherment",
+		"dataEncipherment",
+		"keyAgreement",
+		"keyCertSign",
+		"cRLSign",
+		"encipherOnly",
+		"decipherOnly",
+	}
+
+	x509ExtKeyUsageNames = map[x509.ExtKeyUsage]string{
+		x509.ExtKeyUsageAny:                            "any",
+		x509.ExtKeyUsageServerAuth:                     "serverAuth",
+		x509.ExtKeyUsageClientAuth:                     "clientAuth",
+		x509.ExtKeyUsageCodeSigning:                    "codeSigning",
+		x509.ExtKeyUsageEmailProtection:                "emailProtection",
+		x509.ExtKeyUsageIPSECEndSystem:                 "ipsecEndSystem",
+		x509.ExtKeyUsageIPSECTunnel:                    "ipsecTunnel",
+		x509.ExtKeyUsageIPSECUser:                      "ipsecUser",
+		x509.ExtKeyUsageTimeStamping:                   "timeStamping",
+		x509.ExtKeyUsageOCSPSigning:                    "OCSPSigning",
+		x509.ExtKeyUsageMicrosoftServerGatedCrypto:     "msSGC",
+		x509.ExtKeyUsageNetscapeServerGatedCrypto:      "nsSGC",
+		x509.ExtKeyUsageMicrosoftCommercialCodeSigning: "msCodeCom",
+		x509.ExtKeyUsageMicrosoftKernelCodeSigning:     "msKernelCodeSigning",
+	}
+
+	x509ExtensionNames = map[string]string{
+		"2.5.29.14":               "subjectKeyIdentifier",
+		"2.5.29.15":               "keyUsage",
+		"2.5.29.17":               "subjectAltName",
+		"2.5.29.18":               "issuerAltName",
+		"2.5.29.19":               "basicConstraints",
+		"2.5.29.30":               "nameConstraints",
+		"2.5.29.31":               "cRLDistributionPoints",
+		"2.5.29.32":               "certificatePolicies",
+		"2.5.29.35":               "authorityKeyIdentifier",
+		"2.5.29.36":               "policyConstraints",
+		"2.5.29.37":               "extKeyUsage",
+		"2.5.29.54":               "inhibitAnyPolicy",
+		"1.3.6.1.5.5.7.1.1":       "authorityInfoAccess",
+		"1.3.6.1.5.5.7.48.1.5":    "ocspNoCheck",
+		"1.3.6.1.4.1.11129.2.4.2": "ctSignedCertificateTimestamps",
+	}
+)
+
+// CertificateSummary returns *CertificateSummary of the certificate. The options are passed to X509.CertificateStatus.
+func (x509Utility) CertificateSummary(cert *x509.Certificate, opts ...CertificateStatusOption) *CertificateSummary {
+	sha256Sum := sha256.Sum256(cert.Raw)
+	sha1Sum := sha1.Sum(cert.Raw) // nolint: gosec
+
+	summary := &CertificateSummary{
+		Subject:               cert.Subject.String(),
+		Issuer:                cert.Issuer.String(),
+		SerialNumber:          x509HexColon(cert.SerialNumber.Bytes()),
+		Version:               cert.Version,
+		SignatureAlgorithm:    cert.SignatureAlgorithm.String(),
+		IsCA:                  cert.IsCA,
+		MaxPathLen:            -1,
+		DNSNames:              cert.DNSNames,
+		EmailAddresses:        cert.EmailAddresses,
+		NotBefore:             cert.NotBefore,
+		NotAfter:              cert.NotAfter,
+		SubjectKeyID:          x509HexColon(cert.SubjectKeyId),
+		AuthorityKeyID:        x509HexColon(cert.AuthorityKeyId),
+		OCSPServers:           cert.OCSPServer,
+		IssuingCertificateURL: cert.IssuingCertificateURL,
+		CRLDistributionPoints: cert.CRLDistributionPoints,
+		FingerprintSHA256:     x509HexColon(sha256Sum[:]),
+		FingerprintSHA1:       x509HexColon(sha1Sum[:]),
+		Extensions:            make([]CertificateExtension, 0, len(cert.Extensions)),
+	}
+
+	if summary.SerialNumber == "" {
+		summary.SerialNumber = "00"
+	}
+
+	if info, err := Crypto.KeyInfo(cert.PublicKey); err == nil {
+		summary.PublicKey = info
+	}
+
+	if cert.IsCA && (cert.MaxPathLen > 0 || cert.MaxPathLenZero) {
+		summary.MaxPathLen = cert.MaxPathLen
+	}
+
+	for _, ip := range cert.IPAddresses {
+		summary.IPAddresses = append(summary.IPAddresses, ip.String())
+	}
+
+	for _, uri := range cert.URIs {
+		summary.URIs = append(summary.URIs, uri.String())
+	}
+
+	for i, name := range x509KeyUsageNames {
+		if cert.KeyUsage&(1<<i) != 0 {
+			summary.KeyUsages = append(summary.KeyUsages, name)
+		}
+	}
+
+	for _, usage := range cert.ExtKeyUsage {
+		name, ok := x509ExtKeyUsageNames[usage]
+		if !ok {
+			name = "unknown(" + strconv.Itoa(int(usage)) + ")"
+		}
+
+		summary.ExtKeyUsages = append(summary.ExtKeyUsages, name)
+	}
+
+	for _, oid := range cert.UnknownExtKeyUsage {
+		summary.ExtKeyUsages = append(summary.ExtKeyUsages, oid.String())
+	}
+
+	for _, ext := range cert.Extensions {
+		oid := ext.Id.String()
+		summary.Extensions = append(summary.Extensions, CertificateExtension{OID: oid, Name: x509ExtensionNames[oid], Critical: ext.Critical})
+	}
+
+	// The error is represented by Status.State.
+	summary.Status, _ = X509.CertificateStatus(cert, opts...)
+
+	return summary
+}
+
+// ChainSummary returns CertificateSummaries of the certificates in the passed order.
+// Pass the result of X509.OrderChain or X509ChainReport.Chain to summarize a chain from the leaf.
+func (x509Utility) ChainSummary(certs []*x509.Certificate, opts ...CertificateStatusOption) CertificateSummaries {
+	summaries := make(CertificateSummaries, 0, len(certs))
+	for _, cert := range certs {
+		summaries = append(summaries, X509.CertificateSummary(cert, opts...))
+	}
+
+	return summaries
+}
+
+// WriteJSON writes the summary as indented JSON.
+func (s *CertificateSummary) WriteJSON(w io.Writer) error {
+	return x509WriteJSON(w, s)
+}
+
+// WriteText writes the summary as aligned "Name: value" lines.
+func (s *CertificateSummary) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 1, ' ', 0)
+	s.writeText(tw, "")
+
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("(*tabwriter.Writer).Flush: %w", err)
+	}
+
+	return nil
+}
+
+// String returns the text written by WriteText.
+func (s *CertificateSummary) String() string {
+	buf := new(strings.Builder)
+	_ = s.WriteText(buf)
+
+	return buf.String()
+}
+
+// WriteJSON writes the summaries as an indented JSON array.
+func (s CertificateSummaries) WriteJSON(w io.Writer) error {
+	return x509WriteJSON(w, s)
+}
+
+// WriteText writes each summary under a "Certificate[i]:" heading, aligned across the chain.
+func (s CertificateSummaries) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 1, ' ', 0)
+
+	for i, summary := range s {
+		if i > 0 {
+			fmt.Fprintln(tw)
+		}
+
+		fmt.Fprintf(tw, "Certificate[%d]:\n", i)
+		summary.writeText(tw, "  ")
+	}
+
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("(*tabwriter.Writer).Flush: %w", err)
+	}
+
+	return nil
+}
+
+func (s *CertificateSummary) writeText(w io.Writer, indent string) {
+	line := func(name, value string) {
+		if value != "" {
+			fmt.Fprintf(w, "%s%s:\t%s\n", indent, name, value)
+		}
+	}
+
+	line("Subject", s.Subject)
+	line("Issuer", s.Issuer)
+	line("Serial Number", s.SerialNumber)
+	line("Version", strconv.Itoa(s.Version))
+	line("Signature Algorithm", s.SignatureAlgorithm)
+
+	if s.PublicKey != nil {
+		publicKey := fmt.Sprintf("%s %d bit", s.PublicKey.KeyType, s.PublicKey.BitSize)
+		if s.PublicKey.Curve != "" && s.PublicKey.Curve != s.PublicKey.KeyType {
+			publicKey += " " + s.PublicKey.Curve
+		}
+
+		line("Public Key", publicKey)
+	}
+
+	switch {
+	case !s.IsCA:
+		line("CA", "false")
+	case s.MaxPathLen < 0:
+		line("CA", "true")
+	default:
+		line("CA", fmt.Sprintf("true, max path length %d", s.MaxPathLen))
+	}
+
+	line("Not Before", s.NotBefore.Format(time.RFC3339))
+	line("Not After", s.NotAfter.Format(time.RFC3339))
+
+	if s.Status != nil {
+		switch s.Status.State {
+		case CertificateStateNotYetValid:
+			line("Status", fmt.Sprintf("%s, valid in %s", s.Status.State, s.Status.UntilValid.Round(time.Second)))
+		case CertificateStateExpired:
+			line("Status", fmt.Sprintf("%s %s ago", s.Status.State, (-s.Status.UntilExpiry).Round(time.Second)))
+		default:
+			line("Status", fmt.Sprintf("%s, expires in %s", s.Status.State, s.Status.UntilExpiry.Round(time.Second)))
+		}
+	}
+
+	line("DNS Names", strings.Join(s.DNSNames, ", "))
+	line("IP Addresses", strings.Join(s.IPAddresses, ", "))
+	line("URIs", strings.Join(s.URIs, ", "))
+	line("Email Addresses", strings.Join(s.EmailAddresses, ", "))
+	line("Key Usage", strings.Join(s.KeyUsages, ", "))
+	line("Extended Key Usage", strings.Join(s.ExtKeyUsages, ", "))
+	line("Subject Key ID", s.SubjectKeyID)
+	line("Authority Key ID", s.AuthorityKeyID)
+	line("OCSP Servers", strings.Join(s.OCSPServers, ", "))
+	line("Issuing Certificate URL", strings.Join(s.IssuingCertificateURL, ", "))
+	line("CRL Distribution Points", strings.Join(s.CRLDistributionPoints, ", "))
+	line("SHA-256 Fingerprint", s.FingerprintSHA256)
+	line("SHA-1 Fingerprint", s.FingerprintSHA1)
+
+	for i, ext := range s.Extensions {
+		name := "Extensions"
+		if i > 0 {
+			name = ""
+		}
+
+		value := ext.OID
+		if ext.Name != "" {
+			value += " " + ext.Name
+		}
+
+		if ext.Critical {
+			value += " (critical)"
+		}
+
+		if name == "" {
+			fmt.Fprintf(w, "%s\t%s\n", indent, value)
+		} else {
+			line(name, value)
+		}
+	}
+}
+
+func x509WriteJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	if err := enc.Encode(v); err != nil {
+		return fmt.Errorf("(*json.Encoder).Encode: %w", err)
+	}
+
+	return nil
+}
+
+func x509HexColon(b []byte) string {
+	if len(b) == 0 {
+		return ""
+	}
+
+	s := strings.ToUpper(hex.EncodeToString(b))
+	parts := make([]string, 0, len(b))
+
+	for i := 0; i < len(s); i += 2 {
+		parts = append(parts, s[i:i+2])
+	}
+
+	return strings.Join(parts, ":")
+}
@@ -0,0 +1,136 @@
+// nolint: testpackage
+package nits
+
+import (
+	"bytes"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestX509Utility_CertificateSummary(t *testing.T) {
+	t.Parallel()
+
+	ca := testNewRootCA(t)
+	intermediateKey, err := Crypto.GenerateKey(CryptoECDSA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	intermediate, err := ca.CreateIntermediate(intermediateKey, X509.WithSubject(pkix.Name{CommonName: "intermediate"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	leaf, _, err := intermediate.Issue(testGeneratePublicKey(t),
+		X509.WithSubject(pkix.Name{CommonName: "app.example.com"}),
+		X509.WithSANs("app.example.com", "127.0.0.1", "spiffe://example.com/app", "app@example.com"),
+		X509.WithExtKeyUsage(x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth),
+		X509.WithOCSPServers("http://ocsp.example.com"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clock := X509.WithCertificateStatusClock(func() time.Time { return leaf.NotAfter.Add(-time.Hour) })
+
+	t.Run("success()", func(t *testing.T) {
+		t.Parallel()
+		summary := X509.CertificateSummary(leaf, clock)
+
+		if summary.Subject != "CN=app.example.com" || summary.Issuer != intermediate.Certificate().Subject.String() || summary.IsCA || summary.MaxPathLen != -1 {
+			t.Errorf("unexpected summary: %+v", summary)
+		}
+
+		if summary.PublicKey == nil || summary.PublicKey.KeyType != CryptoKeyTypeEd25519 || summary.PublicKey.BitSize != 256 {
+			t.Errorf("unexpected public key: %+v", summary.PublicKey)
+		}
+
+		if strings.Join(summary.IPAddresses, ",") != "127.0.0.1" || strings.Join(summary.URIs, ",") != "spiffe://example.com/app" || strings.Join(summary.EmailAddresses, ",") != "app@example.com" {
+			t.Errorf("unexpected SANs: %+v", summary)
+		}
+
+		if strings.Join(summary.KeyUsages, ",") != "digitalSignature" || strings.Join(summary.ExtKeyUsages, ",") != "serverAuth,clientAuth" {
+			t.Errorf("unexpected usages: %v %v", summary.KeyUsages, summary.ExtKeyUsages)
+		}
+
+		if len(summary.FingerprintSHA256) != 32*3-1 || len(summary.FingerprintSHA1) != 20*3-1 || summary.AuthorityKeyID != x509HexColon(intermediate.Certificate().SubjectKeyId) {
+			t.Errorf("unexpected identifiers: %+v", summary)
+		}
+
+		if summary.Status.State != CertificateStateExpiringSoon || summary.Status.UntilExpiry != time.Hour {
+			t.Errorf("unexpected status: %+v", summary.Status)
+		}
+
+		names := make([]string, 0, len(summary.Extensions))
+		for _, ext := range summary.Extensions {
+			names = append(names, ext.Name)
+		}
+
+		if joined := strings.Join(names, ","); !strings.Contains(joined, "subjectAltName") || !strings.Contains(joined, "authorityInfoAccess") {
+			t.Errorf("unexpected extensions: %s", joined)
+		}
+
+		text := summary.String()
+		for _, expect := range []string{
+			"Subject:             CN=app.example.com\n",
+			"Public Key:          Ed25519 256 bit\n",
+			"Status:              expiring_soon, expires in 1h0m0s\n",
+			"IP Addresses:        127.0.0.1\n",
+			"Extended Key Usage:  serverAuth, clientAuth\n",
+		} {
+			if !strings.Contains(text, expect) {
+				t.Errorf("%q does not contain %q", text, expect)
+			}
+		}
+	})
+
+	t.Run("success(Chain)", func(t *testing.T) {
+		t.Parallel()
+		summaries := X509.ChainSummary([]*x509.Certificate{leaf, intermediate.Certificate(), ca.Certificate()}, clock)
+
+		if len(summaries) != 3 || !summaries[1].IsCA || summaries[1].MaxPathLen != 0 || summaries[2].MaxPathLen != -1 {
+			t.Fatalf("unexpected summaries: %+v", summaries)
+		}
+
+		buf := new(bytes.Buffer)
+		if err := summaries.WriteJSON(buf); err != nil {
+			t.Fatal(err)
+		}
+
+		var decoded []map[string]interface{}
+		if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(decoded) != 3 || decoded[0]["serialNumber"] != summaries[0].SerialNumber || decoded[2]["status"].(map[string]interface{})["state"] != CertificateStateExpiringSoon {
+			t.Errorf("unexpected JSON: %s", buf)
+		}
+
+		buf.Reset()
+		if err := summaries.WriteText(buf); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, expect := range []string{"Certificate[0]:\n", "\nCertificate[2]:\n", "  CA:                  true, max path length 0\n", "  Subject:             CN=root\n", "  Public Key:          ECDSA 256 bit P-256\n"} {
+			if !strings.Contains(buf.String(), expect) {
+				t.Errorf("%q does not contain %q", buf.String(), expect)
+			}
+		}
+	})
+
+	t.Run("success(Expired)", func(t *testing.T) {
+		t.Parallel()
+		cert, err := X509.ParseCertificatePEM([]byte(testCrtPEMExpiredString))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if summary := X509.CertificateSummary(cert); summary.Status.State != CertificateStateExpired || !strings.Contains(summary.String(), "Status:") {
+			t.Errorf("unexpected summary: %s", summary)
+		}
+	})
+}